@@ -1,60 +1,39 @@
 package app
 
 import (
+	"context"
 	"crypto/tls"
-	"encoding/json"
 	"fmt"
 	gslbsvc "github.com/orange-cloudfoundry/gsloc-go-sdk/gsloc/services/gslb/v1"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials"
-	"os"
 	"os/user"
 	"path/filepath"
 )
 
-type gslocConfig struct {
-	Host       string `json:"host"`
-	Username   string `json:"username"`
-	SkipVerify bool   `json:"skip_verify"`
-}
-
 type Client struct {
 }
 
-func makeGrpcConn(host, username, password string, skipVerify bool, opts ...grpc.DialOption) (*grpc.ClientConn, error) {
+func makeGrpcConn(target *Target, password, storeDir string, opts ...grpc.DialOption) (*grpc.ClientConn, error) {
 	opts = append(opts, grpc.WithTransportCredentials(credentials.NewTLS(&tls.Config{
-		InsecureSkipVerify: skipVerify,
+		InsecureSkipVerify: target.SkipVerify,
 	})))
-	//loginSvc, err := svclogin.NewLoginServiceWithStoreDir(host, username, password, os.TempDir(), opts...)
-	//if err != nil {
-	//	return nil, fmt.Errorf("Error when creating grpc connection: %s", err)
-	//}
-	//_, err = loginSvc.GetRequestMetadata(context.Background())
-	//if err != nil {
-	//	return nil, err
-	//}
-	//return grpc.Dial(host, append(opts, grpc.WithPerRPCCredentials(loginSvc))...)
-	return grpc.Dial(host, opts...)
-}
-
-func retrieveConfig(path string) (*gslocConfig, error) {
-	confRaw, err := os.ReadFile(path)
+	loginSvc, err := newLoginServiceWithStorePath(
+		target.TokenURL, target.ClientID, target.Username, password,
+		filepath.Join(storeDir, target.Name+".json"), target.SkipVerify,
+	)
 	if err != nil {
-		if os.IsNotExist(err) {
-			return nil, fmt.Errorf("config file not found, please login first")
-		}
-		return nil, err
+		return nil, fmt.Errorf("Error when creating grpc connection: %s", err)
 	}
-	config := &gslocConfig{}
-	err = json.Unmarshal(confRaw, config)
+	_, err = loginSvc.GetRequestMetadata(context.Background())
 	if err != nil {
 		return nil, err
 	}
-	return config, nil
+	return grpc.Dial(target.Host, append(opts, grpc.WithPerRPCCredentials(loginSvc))...)
 }
 
-func GetCurrentUsername(path string) string {
-	config, err := retrieveConfig(path)
+func GetCurrentUsername(path, targetName string) string {
+	target, err := GetTarget(path, targetName)
 	if err != nil {
 		me, err := user.Current()
 		if err != nil {
@@ -62,44 +41,59 @@ func GetCurrentUsername(path string) string {
 		}
 		return me.Username
 	}
-	return config.Username
+	return target.Username
 }
 
-func CreateConnFromFile(path string) (*grpc.ClientConn, error) {
-	config, err := retrieveConfig(path)
+// CreateConnFromFile create a connection to the target with given name from config file,
+// current target is used when name is empty.
+func CreateConnFromFile(path, targetName string) (*grpc.ClientConn, error) {
+	target, err := GetTarget(path, targetName)
 	if err != nil {
 		return nil, err
 	}
-	if err != nil {
-		return nil, fmt.Errorf("error when unmarshalling config file: %w", err)
-	}
-	return makeGrpcConn(config.Host, config.Username, "", config.SkipVerify)
+	return makeGrpcConn(target, "", tokenStoreDir(path))
 }
 
-func CreateConn(path string, host, username, password string, skipVerify bool) (*grpc.ClientConn, error) {
-
-	config, err := retrieveConfig(path)
-	if err == nil && host == "" {
-		host = config.Host
-		skipVerify = config.SkipVerify
-	}
-
-	err = os.MkdirAll(filepath.Dir(path), 0755)
+// CreateConn login on given target and store it in config file as the current target.
+// Empty fields in target are taken from previous target with the same name.
+func CreateConn(path string, target Target, password string) (*grpc.ClientConn, error) {
+	config, err := retrieveConfigOrEmpty(path)
 	if err != nil {
 		return nil, err
 	}
-	conn, err := makeGrpcConn(host, username, password, skipVerify)
+	if target.Name == "" {
+		target.Name = config.Current
+	}
+	if target.Name == "" {
+		target.Name = DefaultTargetName
+	}
+	if !targetNameRegex.MatchString(target.Name) {
+		return nil, fmt.Errorf("invalid target name %s, only letters, digits, '.', '_' and '-' are allowed", target.Name)
+	}
+	previous := config.target(target.Name)
+	if previous != nil && target.Host == "" {
+		target.Host = previous.Host
+		target.SkipVerify = previous.SkipVerify
+	}
+	if previous != nil && target.TokenURL == "" {
+		target.TokenURL = previous.TokenURL
+	}
+	if previous != nil && target.ClientID == "" {
+		target.ClientID = previous.ClientID
+	}
+	if target.Host == "" {
+		return nil, fmt.Errorf("host is required for target %s", target.Name)
+	}
+
+	conn, err := makeGrpcConn(&target, password, tokenStoreDir(path))
 	if err != nil {
 		return nil, err
 	}
-	// nolint:errcheck
-	b, _ := json.MarshalIndent(gslocConfig{
-		Host:       host,
-		Username:   username,
-		SkipVerify: skipVerify,
-	}, "", "  ")
-	err = os.WriteFile(path, b, 0644)
+	config.setTarget(&target)
+	config.Current = target.Name
+	err = storeConfig(path, config)
 	if err != nil {
+		conn.Close() // nolint:errcheck
 		return nil, err
 	}
 	return conn, nil
@@ -0,0 +1,178 @@
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+)
+
+const DefaultTargetName = "default"
+
+var targetNameRegex = regexp.MustCompile(`^[a-zA-Z0-9._-]+$`)
+
+type Target struct {
+	Name       string `json:"name"`
+	Host       string `json:"host"`
+	Username   string `json:"username"`
+	SkipVerify bool   `json:"skip_verify"`
+	TokenURL   string `json:"token_url"`
+	ClientID   string `json:"client_id"`
+}
+
+type gslocConfig struct {
+	Current string    `json:"current"`
+	Targets []*Target `json:"targets"`
+
+	// Host is only set by config file made before targets were introduced
+	// it is read for migrating those files to a default target
+	Host       string `json:"host,omitempty"`
+	Username   string `json:"username,omitempty"`
+	SkipVerify bool   `json:"skip_verify,omitempty"`
+}
+
+func (c *gslocConfig) target(name string) *Target {
+	if name == "" {
+		name = c.Current
+	}
+	for _, t := range c.Targets {
+		if t.Name == name {
+			return t
+		}
+	}
+	return nil
+}
+
+func (c *gslocConfig) setTarget(target *Target) {
+	for i, t := range c.Targets {
+		if t.Name == target.Name {
+			c.Targets[i] = target
+			return
+		}
+	}
+	c.Targets = append(c.Targets, target)
+}
+
+func retrieveConfig(path string) (*gslocConfig, error) {
+	confRaw, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("config file not found, please login first")
+		}
+		return nil, err
+	}
+	config := &gslocConfig{}
+	err = json.Unmarshal(confRaw, config)
+	if err != nil {
+		return nil, fmt.Errorf("error when unmarshalling config file: %w", err)
+	}
+	if config.Host != "" && len(config.Targets) == 0 {
+		config.Targets = []*Target{{
+			Name:       DefaultTargetName,
+			Host:       config.Host,
+			Username:   config.Username,
+			SkipVerify: config.SkipVerify,
+		}}
+		config.Current = DefaultTargetName
+	}
+	config.Host = ""
+	config.Username = ""
+	config.SkipVerify = false
+	return config, nil
+}
+
+func retrieveConfigOrEmpty(path string) (*gslocConfig, error) {
+	config, err := retrieveConfig(path)
+	if err == nil {
+		return config, nil
+	}
+	if _, errStat := os.Stat(path); os.IsNotExist(errStat) {
+		return &gslocConfig{}, nil
+	}
+	return nil, err
+}
+
+func storeConfig(path string, config *gslocConfig) error {
+	err := os.MkdirAll(filepath.Dir(path), 0755)
+	if err != nil {
+		return err
+	}
+	b, err := json.MarshalIndent(config, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, b, 0644)
+}
+
+func tokenStoreDir(path string) string {
+	return filepath.Join(filepath.Dir(path), "tokens")
+}
+
+// GetTarget gives target by its name from config file, current target is given when name is empty.
+func GetTarget(path, name string) (*Target, error) {
+	config, err := retrieveConfig(path)
+	if err != nil {
+		return nil, err
+	}
+	target := config.target(name)
+	if target == nil {
+		if name == "" {
+			return nil, fmt.Errorf("no target set, please login first")
+		}
+		return nil, fmt.Errorf("target %s not found", name)
+	}
+	return target, nil
+}
+
+// ListTargets gives all targets from config file and the name of the current one.
+func ListTargets(path string) ([]*Target, string, error) {
+	config, err := retrieveConfig(path)
+	if err != nil {
+		return nil, "", err
+	}
+	return config.Targets, config.Current, nil
+}
+
+// UseTarget set the current target in config file.
+func UseTarget(path, name string) error {
+	config, err := retrieveConfig(path)
+	if err != nil {
+		return err
+	}
+	if config.target(name) == nil {
+		return fmt.Errorf("target %s not found", name)
+	}
+	config.Current = name
+	return storeConfig(path, config)
+}
+
+// DeleteTarget remove target and its token from config file.
+func DeleteTarget(path, name string) error {
+	config, err := retrieveConfig(path)
+	if err != nil {
+		return err
+	}
+	if config.target(name) == nil {
+		return fmt.Errorf("target %s not found", name)
+	}
+	targets := make([]*Target, 0, len(config.Targets))
+	for _, t := range config.Targets {
+		if t.Name == name {
+			continue
+		}
+		targets = append(targets, t)
+	}
+	config.Targets = targets
+	if config.Current == name {
+		config.Current = ""
+		if len(targets) > 0 {
+			config.Current = targets[0].Name
+		}
+	}
+	err = os.Remove(filepath.Join(tokenStoreDir(path), name+".json"))
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return storeConfig(path, config)
+}
@@ -0,0 +1,70 @@
+package app_test
+
+import (
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/orange-cloudfoundry/gsloc-cli/app"
+)
+
+var _ = Describe("Config", func() {
+	var configPath string
+
+	BeforeEach(func() {
+		configPath = filepath.Join(GinkgoT().TempDir(), "config.json")
+		err := os.WriteFile(configPath, []byte(`{
+  "current": "staging",
+  "targets": [
+    {"name": "staging", "host": "staging:443", "username": "alice"},
+    {"name": "prod", "host": "prod:443", "username": "bob"}
+  ]
+}`), 0644)
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	It("should give current target when no name is given", func() {
+		target, err := app.GetTarget(configPath, "")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(target.Host).To(Equal("staging:443"))
+
+		target, err = app.GetTarget(configPath, "prod")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(target.Host).To(Equal("prod:443"))
+		Expect(app.GetCurrentUsername(configPath, "prod")).To(Equal("bob"))
+	})
+
+	It("should change current target", func() {
+		Expect(app.UseTarget(configPath, "prod")).To(Succeed())
+		_, current, err := app.ListTargets(configPath)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(current).To(Equal("prod"))
+
+		Expect(app.UseTarget(configPath, "unknown")).To(MatchError(ContainSubstring("not found")))
+	})
+
+	It("should delete target and move current to remaining one", func() {
+		Expect(app.DeleteTarget(configPath, "staging")).To(Succeed())
+		targets, current, err := app.ListTargets(configPath)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(targets).To(HaveLen(1))
+		Expect(current).To(Equal("prod"))
+	})
+
+	It("should migrate config without targets to a default target", func() {
+		err := os.WriteFile(configPath, []byte(`{"host": "old:443", "username": "carol", "skip_verify": true}`), 0644)
+		Expect(err).ToNot(HaveOccurred())
+
+		targets, current, err := app.ListTargets(configPath)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(current).To(Equal(app.DefaultTargetName))
+		Expect(targets).To(HaveLen(1))
+		Expect(*targets[0]).To(Equal(app.Target{
+			Name:       app.DefaultTargetName,
+			Host:       "old:443",
+			Username:   "carol",
+			SkipVerify: true,
+		}))
+	})
+})
@@ -0,0 +1,118 @@
+package app
+
+import (
+	"bufio"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+const (
+	AuditResultSuccess = "success"
+	AuditResultError   = "error"
+)
+
+// AuditRecord is one line of the journal, it is written for each call which changes something on a target.
+// Request and Prior are protos in json, Prior is the state before the call and is empty when object did not exist,
+// PriorError is set when state before the call could not be retrieved.
+type AuditRecord struct {
+	Operation  string          `json:"operation"`
+	Time       time.Time       `json:"time"`
+	Target     string          `json:"target"`
+	Host       string          `json:"host"`
+	Username   string          `json:"username"`
+	Command    string          `json:"command"`
+	Method     string          `json:"method"`
+	Objects    []string        `json:"objects"`
+	Request    json.RawMessage `json:"request"`
+	Prior      json.RawMessage `json:"prior,omitempty"`
+	PriorError string          `json:"prior_error,omitempty"`
+	Result     string          `json:"result"`
+	Error      string          `json:"error,omitempty"`
+}
+
+// Journal is an append-only file of audit records in json lines.
+type Journal struct {
+	path string
+	mu   sync.Mutex
+}
+
+func NewJournal(path string) *Journal {
+	return &Journal{path: path}
+}
+
+// JournalPath gives journal path next to config file.
+func JournalPath(configPath string) string {
+	return filepath.Join(filepath.Dir(configPath), "journal.jsonl")
+}
+
+// NewOperationId gives an id shared by all records made by one command run.
+func NewOperationId(now time.Time) string {
+	b := make([]byte, 3)
+	_, err := rand.Read(b)
+	if err != nil {
+		panic(err)
+	}
+	return now.UTC().Format("20060102T150405Z") + "-" + hex.EncodeToString(b)
+}
+
+func (j *Journal) Path() string {
+	return j.path
+}
+
+func (j *Journal) Append(record *AuditRecord) error {
+	b, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	err = os.MkdirAll(filepath.Dir(j.path), 0700)
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(j.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	defer f.Close() // nolint:errcheck
+	_, err = f.Write(append(b, '\n'))
+	return err
+}
+
+// Records gives all records from the oldest to the newest, an absent journal is seen as empty.
+func (j *Journal) Records() ([]*AuditRecord, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	f, err := os.Open(j.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []*AuditRecord{}, nil
+		}
+		return nil, err
+	}
+	defer f.Close() // nolint:errcheck
+
+	records := make([]*AuditRecord, 0)
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	nbLine := 0
+	for scanner.Scan() {
+		nbLine++
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		record := &AuditRecord{}
+		err = json.Unmarshal(scanner.Bytes(), record)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read journal %s at line %d: %w", j.path, nbLine, err)
+		}
+		records = append(records, record)
+	}
+	return records, scanner.Err()
+}
@@ -0,0 +1,63 @@
+package app_test
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/orange-cloudfoundry/gsloc-cli/app"
+)
+
+var _ = Describe("Journal", func() {
+	var journal *app.Journal
+
+	BeforeEach(func() {
+		journal = app.NewJournal(app.JournalPath(filepath.Join(GinkgoT().TempDir(), "gsloc", "config.json")))
+	})
+
+	It("should give no records when journal does not exist", func() {
+		records, err := journal.Records()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(records).To(BeEmpty())
+	})
+
+	It("should append records as json lines", func() {
+		now := time.Date(2023, 1, 1, 10, 0, 0, 0, time.UTC)
+		operation := app.NewOperationId(now)
+		Expect(operation).To(HavePrefix("20230101T100000Z-"))
+
+		Expect(journal.Append(&app.AuditRecord{
+			Operation: operation,
+			Time:      now,
+			Username:  "alice",
+			Method:    "SetEntry",
+			Objects:   []string{"a.example.com."},
+			Request:   json.RawMessage(`{"entry":{"fqdn":"a.example.com."}}`),
+			Result:    app.AuditResultSuccess,
+		})).To(Succeed())
+		Expect(journal.Append(&app.AuditRecord{
+			Operation: operation,
+			Time:      now,
+			Username:  "alice",
+			Method:    "DeleteEntry",
+			Objects:   []string{"b.example.com."},
+			Request:   json.RawMessage(`{"fqdn":"b.example.com."}`),
+			Result:    app.AuditResultError,
+			Error:     "not found",
+		})).To(Succeed())
+
+		content, err := os.ReadFile(journal.Path())
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(content)).To(HavePrefix(`{"operation":"` + operation + `"`))
+
+		records, err := journal.Records()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(records).To(HaveLen(2))
+		Expect(records[0].Method).To(Equal("SetEntry"))
+		Expect(records[0].Prior).To(BeEmpty())
+		Expect(records[1].Error).To(Equal("not found"))
+	})
+})
@@ -0,0 +1,233 @@
+package app
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	DefaultClientID = "gsloc-cli"
+	// tokens are refreshed a bit before their real expiry to not send an expired token
+	// to server during a long rpc call
+	expiryDelta = 10 * time.Second
+)
+
+type token struct {
+	AccessToken  string    `json:"access_token"`
+	TokenType    string    `json:"token_type"`
+	RefreshToken string    `json:"refresh_token"`
+	Expiry       time.Time `json:"expiry"`
+}
+
+func (t *token) valid() bool {
+	if t == nil || t.AccessToken == "" {
+		return false
+	}
+	if t.Expiry.IsZero() {
+		return true
+	}
+	return time.Now().Add(expiryDelta).Before(t.Expiry)
+}
+
+func (t *token) authorization() string {
+	tokenType := t.TokenType
+	if tokenType == "" || strings.EqualFold(tokenType, "bearer") {
+		tokenType = "Bearer"
+	}
+	return tokenType + " " + t.AccessToken
+}
+
+type tokenResponse struct {
+	AccessToken  string `json:"access_token"`
+	TokenType    string `json:"token_type"`
+	RefreshToken string `json:"refresh_token"`
+	ExpiresIn    int64  `json:"expires_in"`
+	Error        string `json:"error"`
+	ErrorDesc    string `json:"error_description"`
+}
+
+// loginService exchange username and password against a token with oauth2 password grant
+// and give it to each grpc call, token is cached in store path and refreshed when expired.
+// It implements credentials.PerRPCCredentials.
+type loginService struct {
+	tokenURL   string
+	clientID   string
+	username   string
+	password   string
+	storePath  string
+	httpClient *http.Client
+
+	mu  sync.Mutex
+	tok *token
+}
+
+func newLoginServiceWithStorePath(tokenURL, clientID, username, password, storePath string, skipVerify bool) (*loginService, error) {
+	if tokenURL == "" {
+		return nil, fmt.Errorf("token url is not set, please login with --token-url or GSLOC_TOKEN_URL")
+	}
+	if clientID == "" {
+		clientID = DefaultClientID
+	}
+	svc := &loginService{
+		tokenURL:  tokenURL,
+		clientID:  clientID,
+		username:  username,
+		password:  password,
+		storePath: storePath,
+		httpClient: &http.Client{
+			Timeout: 30 * time.Second,
+			Transport: &http.Transport{
+				Proxy: http.ProxyFromEnvironment,
+				TLSClientConfig: &tls.Config{
+					InsecureSkipVerify: skipVerify, // nolint:gosec
+				},
+			},
+		},
+	}
+	// when a password is given this is a new login, previous token must not be reused
+	if password != "" {
+		return svc, nil
+	}
+	tok, err := svc.loadToken()
+	if err != nil {
+		return nil, err
+	}
+	svc.tok = tok
+	return svc, nil
+}
+
+func (s *loginService) GetRequestMetadata(ctx context.Context, _ ...string) (map[string]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	tok, err := s.token(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]string{
+		"authorization": tok.authorization(),
+	}, nil
+}
+
+func (s *loginService) RequireTransportSecurity() bool {
+	return true
+}
+
+func (s *loginService) token(ctx context.Context) (*token, error) {
+	if s.tok.valid() {
+		return s.tok, nil
+	}
+	var tok *token
+	var err error
+	if s.tok != nil && s.tok.RefreshToken != "" {
+		tok, err = s.requestToken(ctx, url.Values{
+			"grant_type":    {"refresh_token"},
+			"refresh_token": {s.tok.RefreshToken},
+		})
+	}
+	if tok == nil {
+		if s.password == "" {
+			if err != nil {
+				return nil, fmt.Errorf("session expired, please login again: %w", err)
+			}
+			return nil, fmt.Errorf("session expired, please login again")
+		}
+		tok, err = s.requestToken(ctx, url.Values{
+			"grant_type": {"password"},
+			"username":   {s.username},
+			"password":   {s.password},
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	// some servers do not give back a refresh token when refreshing, keep the previous one
+	if tok.RefreshToken == "" && s.tok != nil {
+		tok.RefreshToken = s.tok.RefreshToken
+	}
+	s.tok = tok
+	err = s.storeToken(tok)
+	if err != nil {
+		return nil, err
+	}
+	return tok, nil
+}
+
+func (s *loginService) requestToken(ctx context.Context, values url.Values) (*token, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.tokenURL, strings.NewReader(values.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("error when creating token request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	req.SetBasicAuth(url.QueryEscape(s.clientID), "")
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error when requesting token: %w", err)
+	}
+	defer resp.Body.Close() // nolint:errcheck
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, fmt.Errorf("error when reading token response: %w", err)
+	}
+	tokResp := &tokenResponse{}
+	// nolint:errcheck
+	json.Unmarshal(body, tokResp)
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		if tokResp.Error != "" {
+			return nil, fmt.Errorf("authentication failed: %s %s", tokResp.Error, tokResp.ErrorDesc)
+		}
+		return nil, fmt.Errorf("authentication failed: http code %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	if tokResp.AccessToken == "" {
+		return nil, fmt.Errorf("authentication failed: no access token given by server")
+	}
+	tok := &token{
+		AccessToken:  tokResp.AccessToken,
+		TokenType:    tokResp.TokenType,
+		RefreshToken: tokResp.RefreshToken,
+	}
+	if tokResp.ExpiresIn > 0 {
+		tok.Expiry = time.Now().Add(time.Duration(tokResp.ExpiresIn) * time.Second)
+	}
+	return tok, nil
+}
+
+func (s *loginService) loadToken() (*token, error) {
+	b, err := os.ReadFile(s.storePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("no token found, please login first")
+		}
+		return nil, err
+	}
+	tok := &token{}
+	err = json.Unmarshal(b, tok)
+	if err != nil {
+		return nil, fmt.Errorf("error when unmarshalling token file: %w", err)
+	}
+	return tok, nil
+}
+
+func (s *loginService) storeToken(tok *token) error {
+	err := os.MkdirAll(filepath.Dir(s.storePath), 0700)
+	if err != nil {
+		return err
+	}
+	b, err := json.MarshalIndent(tok, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(s.storePath, b, 0600)
+}
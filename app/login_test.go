@@ -0,0 +1,107 @@
+package app_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/orange-cloudfoundry/gsloc-cli/app"
+)
+
+var _ = Describe("Login", func() {
+	var server *httptest.Server
+	var configPath string
+	var grants []string
+	var expiresIn int
+
+	BeforeEach(func() {
+		grants = make([]string, 0)
+		expiresIn = 3600
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			defer GinkgoRecover()
+			Expect(r.ParseForm()).To(Succeed())
+			grants = append(grants, r.PostForm.Get("grant_type"))
+			if r.PostForm.Get("grant_type") == "password" && r.PostForm.Get("password") != "secret" {
+				w.WriteHeader(http.StatusUnauthorized)
+				w.Write([]byte(`{"error":"unauthorized","error_description":"bad credentials"}`)) // nolint:errcheck
+				return
+			}
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(map[string]any{ // nolint:errcheck
+				"access_token":  "token-" + r.PostForm.Get("grant_type"),
+				"token_type":    "bearer",
+				"refresh_token": "refresh",
+				"expires_in":    expiresIn,
+			})
+		}))
+		configPath = filepath.Join(GinkgoT().TempDir(), "config.json")
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	readToken := func() map[string]any {
+		b, err := os.ReadFile(filepath.Join(filepath.Dir(configPath), "tokens", "default.json"))
+		Expect(err).ToNot(HaveOccurred())
+		tok := make(map[string]any)
+		Expect(json.Unmarshal(b, &tok)).To(Succeed())
+		return tok
+	}
+
+	It("should exchange credentials against a token and store it", func() {
+		conn, err := app.CreateConn(configPath, app.Target{Host: "localhost:8443", TokenURL: server.URL, Username: "user"}, "secret")
+		Expect(err).ToNot(HaveOccurred())
+		defer conn.Close() // nolint:errcheck
+
+		Expect(grants).To(Equal([]string{"password"}))
+		Expect(readToken()).To(HaveKeyWithValue("access_token", "token-password"))
+		Expect(app.GetCurrentUsername(configPath, "")).To(Equal("user"))
+	})
+
+	It("should fail with bad credentials", func() {
+		_, err := app.CreateConn(configPath, app.Target{Host: "localhost:8443", TokenURL: server.URL, Username: "user"}, "bad")
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("bad credentials"))
+	})
+
+	It("should reuse stored token when creating connection from file", func() {
+		conn, err := app.CreateConn(configPath, app.Target{Host: "localhost:8443", TokenURL: server.URL, Username: "user"}, "secret")
+		Expect(err).ToNot(HaveOccurred())
+		conn.Close() // nolint:errcheck
+
+		conn, err = app.CreateConnFromFile(configPath, "")
+		Expect(err).ToNot(HaveOccurred())
+		conn.Close() // nolint:errcheck
+		Expect(grants).To(Equal([]string{"password"}))
+	})
+
+	It("should refresh token when expired", func() {
+		expiresIn = 1
+		conn, err := app.CreateConn(configPath, app.Target{Host: "localhost:8443", TokenURL: server.URL, Username: "user"}, "secret")
+		Expect(err).ToNot(HaveOccurred())
+		conn.Close() // nolint:errcheck
+		time.Sleep(10 * time.Millisecond)
+
+		conn, err = app.CreateConnFromFile(configPath, "")
+		Expect(err).ToNot(HaveOccurred())
+		conn.Close() // nolint:errcheck
+		Expect(grants).To(Equal([]string{"password", "refresh_token"}))
+		Expect(readToken()).To(HaveKeyWithValue("access_token", "token-refresh_token"))
+	})
+
+	It("should ask for token url when it is not set", func() {
+		_, err := app.CreateConn(configPath, app.Target{Host: "localhost:8443", Username: "user"}, "secret")
+		Expect(err).To(MatchError(ContainSubstring("--token-url")))
+	})
+
+	It("should ask to login when no token has been stored", func() {
+		_, err := app.CreateConnFromFile(configPath, "")
+		Expect(err).To(MatchError(ContainSubstring("please login first")))
+	})
+})
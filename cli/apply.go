@@ -0,0 +1,258 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"github.com/ArthurHlt/go-flags"
+	msg "github.com/ArthurHlt/messages"
+	"github.com/homeport/dyff/pkg/dyff"
+	"github.com/orange-cloudfoundry/gsloc-go-sdk/gsloc/api/config/entries/v1"
+	hcconf "github.com/orange-cloudfoundry/gsloc-go-sdk/gsloc/api/config/healthchecks/v1"
+	gslbsvc "github.com/orange-cloudfoundry/gsloc-go-sdk/gsloc/services/gslb/v1"
+	"sort"
+	"strings"
+)
+
+const (
+	changeCreate = "create"
+	changeUpdate = "update"
+	changeDelete = "delete"
+)
+
+type Apply struct {
+	File       flags.Filename `short:"f" long:"file" description:"Path to a json or yml file or to a directory of files with entries definitions (multi-document yml are supported)" required:"true"`
+	Prune      bool           `long:"prune" description:"Delete entries which have managed tag but are not in definitions, --managed-tag must be set"`
+	ManagedTag string         `long:"managed-tag" description:"Tag added to every applied entry when set, used to find entries to delete with --prune (e.g. managed-by-gslocli)"`
+	Force      bool           `long:"force" description:"Apply changes without confirmation"`
+
+	client gslbsvc.GSLBClient
+}
+
+type EntryChange struct {
+	Action   string
+	Fqdn     string
+	Previous *gslbsvc.SetEntryRequest
+	Current  *gslbsvc.SetEntryRequest
+	Report   dyff.Report
+}
+
+var apply Apply
+
+func (c *Apply) SetClient(client gslbsvc.GSLBClient) {
+	c.client = client
+}
+
+func (c *Apply) Execute([]string) error {
+	manifests, err := LoadEntryManifests(string(c.File))
+	if err != nil {
+		return err
+	}
+	for _, manifest := range manifests {
+		AddTag(manifest.Msg.GetEntry(), c.ManagedTag)
+	}
+
+	entsResp, err := c.client.ListEntries(context.Background(), &gslbsvc.ListEntriesRequest{})
+	if err != nil {
+		return err
+	}
+	changes, err := EntriesChanges(manifests, entsResp.GetEntries())
+	if err != nil {
+		return err
+	}
+	if c.Prune {
+		pruneChanges, err := PruneChanges(manifests, entsResp.GetEntries(), c.ManagedTag)
+		if err != nil {
+			return err
+		}
+		changes = append(changes, pruneChanges...)
+	}
+
+	if len(changes) == 0 {
+		msg.Info("Nothing to do, entries are up to date.")
+		return nil
+	}
+	err = PrintEntriesChanges(changes)
+	if err != nil {
+		return err
+	}
+	confirm, err := Confirm(c.Force)
+	if err != nil {
+		return err
+	}
+	if !confirm {
+		return nil
+	}
+	return c.applyChanges(changes)
+}
+
+// AddTag add tag to entry if not already present, nothing is done if tag is empty.
+func AddTag(entry *entries.Entry, tag string) {
+	if tag == "" || hasTag(entry.GetTags(), tag) {
+		return
+	}
+	entry.Tags = append(entry.Tags, tag)
+}
+
+// PruneChanges gives entries on server having managed tag but not in definitions.
+func PruneChanges(manifests []*Manifest[*gslbsvc.SetEntryRequest], serverEntries []*gslbsvc.GetEntryResponse, managedTag string) ([]*EntryChange, error) {
+	if managedTag == "" {
+		return nil, fmt.Errorf("--managed-tag must be set to prune entries")
+	}
+	inManifests := make(map[string]bool)
+	for _, manifest := range manifests {
+		inManifests[manifest.Msg.GetEntry().GetFqdn()] = true
+	}
+	changes := make([]*EntryChange, 0)
+	for _, ent := range serverEntries {
+		fqdn := ent.GetEntry().GetFqdn()
+		if inManifests[fqdn] || !hasTag(ent.GetEntry().GetTags(), managedTag) {
+			continue
+		}
+		previous := &gslbsvc.SetEntryRequest{
+			Entry:       ent.GetEntry(),
+			Healthcheck: ent.GetHealthcheck(),
+		}
+		report, err := ProtoDiffReport(previous, nil)
+		if err != nil {
+			return nil, err
+		}
+		changes = append(changes, &EntryChange{
+			Action:   changeDelete,
+			Fqdn:     fqdn,
+			Previous: previous,
+			Report:   report,
+		})
+	}
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Fqdn < changes[j].Fqdn
+	})
+	return changes, nil
+}
+
+func (c *Apply) applyChanges(changes []*EntryChange) error {
+	nbFailed := 0
+	for _, change := range changes {
+		var err error
+		if change.Action == changeDelete {
+			_, err = c.client.DeleteEntry(context.Background(), &gslbsvc.DeleteEntryRequest{
+				Fqdn: change.Fqdn,
+			})
+		} else {
+			_, err = c.client.SetEntry(context.Background(), change.Current)
+		}
+		if err != nil {
+			nbFailed++
+			msg.Error(fmt.Sprintf("Failed to %s entry %s: %s", change.Action, change.Fqdn, err.Error()))
+			continue
+		}
+		msg.Successf("Entry %s %sd successfully.", msg.Cyan(change.Fqdn), change.Action)
+	}
+	if nbFailed > 0 {
+		return fmt.Errorf("%d on %d changes failed", nbFailed, len(changes))
+	}
+	return nil
+}
+
+// LoadEntryManifests load entries definitions from a file or a directory
+// and set fqdn in canonical form.
+func LoadEntryManifests(path string) ([]*Manifest[*gslbsvc.SetEntryRequest], error) {
+	manifests, err := LoadManifests[*gslbsvc.SetEntryRequest](path)
+	if err != nil {
+		return nil, err
+	}
+	sources := make(map[string]string)
+	for _, manifest := range manifests {
+		if manifest.Msg.GetEntry().GetFqdn() == "" {
+			return nil, fmt.Errorf("%s: entry fqdn is required", manifest.Source)
+		}
+		if manifest.Msg.Healthcheck == nil {
+			manifest.Msg.Healthcheck = &hcconf.HealthCheck{}
+		}
+		fqdn := strings.ToLower(Fqdn(manifest.Msg.GetEntry().GetFqdn()))
+		manifest.Msg.GetEntry().Fqdn = fqdn
+		if prevSource, ok := sources[fqdn]; ok {
+			return nil, fmt.Errorf("%s: entry %s is already defined in %s", manifest.Source, fqdn, prevSource)
+		}
+		sources[fqdn] = manifest.Source
+	}
+	return manifests, nil
+}
+
+// EntriesChanges compare entries definitions to entries on server, unchanged entries are not given back.
+func EntriesChanges(manifests []*Manifest[*gslbsvc.SetEntryRequest], serverEntries []*gslbsvc.GetEntryResponse) ([]*EntryChange, error) {
+	serverEntriesMap := make(map[string]*gslbsvc.GetEntryResponse)
+	for _, ent := range serverEntries {
+		serverEntriesMap[ent.GetEntry().GetFqdn()] = ent
+	}
+	changes := make([]*EntryChange, 0)
+	for _, manifest := range manifests {
+		fqdn := manifest.Msg.GetEntry().GetFqdn()
+		change := &EntryChange{
+			Action:  changeCreate,
+			Fqdn:    fqdn,
+			Current: manifest.Msg,
+		}
+		var err error
+		if ent, ok := serverEntriesMap[fqdn]; ok {
+			change.Action = changeUpdate
+			change.Previous = &gslbsvc.SetEntryRequest{
+				Entry:       ent.GetEntry(),
+				Healthcheck: ent.GetHealthcheck(),
+			}
+			change.Report, err = ProtoDiffReport(change.Previous, change.Current)
+		} else {
+			change.Report, err = ProtoDiffReport(nil, change.Current)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if len(change.Report.Diffs) == 0 {
+			continue
+		}
+		changes = append(changes, change)
+	}
+	return changes, nil
+}
+
+func PrintEntriesChanges(changes []*EntryChange) error {
+	if IsMachineDiffFormat(opts.DiffFormat) {
+		return WriteEntriesChangesMachine(msg.Output(), changes, opts.DiffFormat)
+	}
+	nbByAction := make(map[string]int)
+	msg.Info("Changes to be made:")
+	for _, change := range changes {
+		nbByAction[change.Action]++
+		msg.Printf("━━━━━\n")
+		msg.Infof("%s entry %s", strings.ToUpper(change.Action[:1])+change.Action[1:], msg.Cyan(change.Fqdn))
+		err := WriteProtoDiff(msg.Output(), change.Fqdn, change.Previous, change.Current, change.Report, opts.DiffFormat)
+		if err != nil {
+			return err
+		}
+	}
+	msg.Printf("━━━━━\n")
+	msg.Infof("Plan: %d to create, %d to update, %d to delete.",
+		nbByAction[changeCreate], nbByAction[changeUpdate], nbByAction[changeDelete])
+	return nil
+}
+
+func hasTag(tags []string, tag string) bool {
+	for _, t := range tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
+
+func init() {
+	desc := "Create, update or delete entries to match definitions from a file or a directory."
+	cmd, err := parser.AddCommand(
+		"apply",
+		desc,
+		desc,
+		&apply)
+	if err != nil {
+		panic(err)
+	}
+	cmd.Aliases = []string{"ap"}
+}
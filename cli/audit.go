@@ -0,0 +1,219 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	msg "github.com/ArthurHlt/messages"
+	"github.com/orange-cloudfoundry/gsloc-cli/app"
+	gslbsvc "github.com/orange-cloudfoundry/gsloc-go-sdk/gsloc/services/gslb/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"os/user"
+	"strings"
+	"time"
+)
+
+// AuditClient write in journal every call which changes something on target,
+// state before the call is fetched first to be kept in the record.
+type AuditClient struct {
+	gslbsvc.GSLBClient
+
+	journal *app.Journal
+	// base is copied in each record, it holds operation, target, host, username and command
+	base app.AuditRecord
+}
+
+func NewAuditClient(client gslbsvc.GSLBClient, journal *app.Journal, base app.AuditRecord) *AuditClient {
+	return &AuditClient{
+		GSLBClient: client,
+		journal:    journal,
+		base:       base,
+	}
+}
+
+// auditClient wrap client for current target and command, client is given as is when target can't be read.
+func auditClient(client gslbsvc.GSLBClient) gslbsvc.GSLBClient {
+	target, err := app.GetTarget(ExpandConfigPath(), opts.Target)
+	if err != nil {
+		return client
+	}
+	username := target.Username
+	if username == "" {
+		if me, err := user.Current(); err == nil {
+			username = me.Username
+		}
+	}
+	return NewAuditClient(client, app.NewJournal(app.JournalPath(ExpandConfigPath())), app.AuditRecord{
+		Operation: app.NewOperationId(time.Now()),
+		Target:    target.Name,
+		Host:      target.Host,
+		Username:  username,
+		Command:   activeCommandName(),
+	})
+}
+
+func activeCommandName() string {
+	names := make([]string, 0)
+	for cmd := parser.Active; cmd != nil; cmd = cmd.Active {
+		names = append(names, cmd.Name)
+	}
+	return strings.Join(names, " ")
+}
+
+func (c *AuditClient) Operation() string {
+	return c.base.Operation
+}
+
+func (c *AuditClient) SetEntry(ctx context.Context, in *gslbsvc.SetEntryRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	prior, priorErr := c.priorEntry(ctx, in.GetEntry().GetFqdn())
+	resp, err := c.GSLBClient.SetEntry(ctx, in, opts...)
+	c.record("SetEntry", []string{in.GetEntry().GetFqdn()}, in, prior, priorErr, err)
+	return resp, err
+}
+
+func (c *AuditClient) DeleteEntry(ctx context.Context, in *gslbsvc.DeleteEntryRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	prior, priorErr := c.priorEntry(ctx, in.GetFqdn())
+	resp, err := c.GSLBClient.DeleteEntry(ctx, in, opts...)
+	c.record("DeleteEntry", []string{in.GetFqdn()}, in, prior, priorErr, err)
+	return resp, err
+}
+
+func (c *AuditClient) SetHealthCheck(ctx context.Context, in *gslbsvc.SetHealthCheckRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	var prior json.RawMessage
+	hcResp, priorErr := c.GSLBClient.GetHealthCheck(ctx, &gslbsvc.GetHealthCheckRequest{
+		Fqdn: in.GetFqdn(),
+	})
+	if priorErr == nil {
+		prior = protoToRawJson(&gslbsvc.SetHealthCheckRequest{
+			Fqdn:        in.GetFqdn(),
+			Healthcheck: hcResp.GetHealthcheck(),
+		})
+	} else if isNotFound(priorErr) {
+		priorErr = nil
+	}
+	resp, err := c.GSLBClient.SetHealthCheck(ctx, in, opts...)
+	c.record("SetHealthCheck", []string{in.GetFqdn()}, in, prior, priorErr, err)
+	return resp, err
+}
+
+func (c *AuditClient) SetMember(ctx context.Context, in *gslbsvc.SetMemberRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	prior, priorErr := c.priorMember(ctx, in.GetFqdn(), in.GetMember().GetIp())
+	resp, err := c.GSLBClient.SetMember(ctx, in, opts...)
+	c.record("SetMember", []string{in.GetFqdn() + " " + in.GetMember().GetIp()}, in, prior, priorErr, err)
+	return resp, err
+}
+
+func (c *AuditClient) DeleteMember(ctx context.Context, in *gslbsvc.DeleteMemberRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	prior, priorErr := c.priorMember(ctx, in.GetFqdn(), in.GetIp())
+	resp, err := c.GSLBClient.DeleteMember(ctx, in, opts...)
+	c.record("DeleteMember", []string{in.GetFqdn() + " " + in.GetIp()}, in, prior, priorErr, err)
+	return resp, err
+}
+
+// SetMembersStatus use a dry run to find members which will be updated, their state is kept
+// as a json list of set member requests.
+func (c *AuditClient) SetMembersStatus(ctx context.Context, in *gslbsvc.SetMembersStatusRequest, opts ...grpc.CallOption) (*gslbsvc.SetMembersStatusResponse, error) {
+	if in.GetDryRun() {
+		return c.GSLBClient.SetMembersStatus(ctx, in, opts...)
+	}
+	dryRunReq := proto.Clone(in).(*gslbsvc.SetMembersStatusRequest)
+	dryRunReq.DryRun = true
+	objects := make([]string, 0)
+	priors := make([]json.RawMessage, 0)
+	dryRunResp, priorErr := c.GSLBClient.SetMembersStatus(ctx, dryRunReq, opts...)
+	for _, info := range dryRunResp.GetUpdated() {
+		for _, ip := range info.GetIps() {
+			objects = append(objects, info.GetFqdn()+" "+ip)
+			prior, err := c.priorMember(ctx, info.GetFqdn(), ip)
+			if err != nil && priorErr == nil {
+				priorErr = err
+			}
+			if prior != nil {
+				priors = append(priors, prior)
+			}
+		}
+	}
+	prior, err := json.Marshal(priors)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.GSLBClient.SetMembersStatus(ctx, in, opts...)
+	c.record("SetMembersStatus", objects, in, prior, priorErr, err)
+	return resp, err
+}
+
+// priorEntry gives entry as a set entry request, nil is given without error when entry does not exist.
+func (c *AuditClient) priorEntry(ctx context.Context, fqdn string) (json.RawMessage, error) {
+	entResp, err := c.GSLBClient.GetEntry(ctx, &gslbsvc.GetEntryRequest{
+		Fqdn: fqdn,
+	})
+	if err != nil {
+		if isNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return protoToRawJson(&gslbsvc.SetEntryRequest{
+		Entry:       entResp.GetEntry(),
+		Healthcheck: entResp.GetHealthcheck(),
+	}), nil
+}
+
+// priorMember gives member as a set member request, nil is given without error when member does not exist.
+func (c *AuditClient) priorMember(ctx context.Context, fqdn, ip string) (json.RawMessage, error) {
+	resp, err := c.GSLBClient.GetMember(ctx, &gslbsvc.GetMemberRequest{
+		Fqdn: fqdn,
+		Ip:   ip,
+	})
+	if err != nil {
+		if isNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return protoToRawJson(&gslbsvc.SetMemberRequest{
+		Fqdn:   fqdn,
+		Member: resp.GetMember(),
+	}), nil
+}
+
+// record write call in journal, a failure to write is only a warning as the call has already been made.
+func (c *AuditClient) record(method string, objects []string, req proto.Message, prior json.RawMessage, priorErr, callErr error) {
+	record := c.base
+	record.Time = time.Now().UTC()
+	record.Method = method
+	record.Objects = objects
+	record.Request = protoToRawJson(req)
+	record.Prior = prior
+	if priorErr != nil {
+		record.PriorError = priorErr.Error()
+	}
+	record.Result = app.AuditResultSuccess
+	if callErr != nil {
+		record.Result = app.AuditResultError
+		record.Error = callErr.Error()
+	}
+	err := c.journal.Append(&record)
+	if err != nil {
+		msg.Warning(fmt.Sprintf("Failed to write %s in journal %s: %s", method, c.journal.Path(), err.Error()))
+	}
+}
+
+func isNotFound(err error) bool {
+	return status.Code(err) == codes.NotFound || strings.Contains(err.Error(), "not found")
+}
+
+func protoToRawJson(pMsg proto.Message) json.RawMessage {
+	b, err := protojson.MarshalOptions{
+		UseProtoNames: true,
+	}.Marshal(pMsg)
+	if err != nil {
+		return nil
+	}
+	return b
+}
@@ -0,0 +1,106 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/orange-cloudfoundry/gsloc-cli/app"
+	"github.com/orange-cloudfoundry/gsloc-go-sdk/gsloc/api/config/entries/v1"
+	gslbsvc "github.com/orange-cloudfoundry/gsloc-go-sdk/gsloc/services/gslb/v1"
+)
+
+func TestAuditClient(t *testing.T) {
+	journal := app.NewJournal(filepath.Join(t.TempDir(), "journal.jsonl"))
+	fake := &fakeClient{
+		setMembersStatusResp: &gslbsvc.SetMembersStatusResponse{
+			Updated: []*gslbsvc.SetMembersStatusResponse_Info{{Fqdn: "a.example.com.", Ips: []string{"10.0.0.1"}}},
+		},
+	}
+	client := NewAuditClient(fake, journal, app.AuditRecord{
+		Operation: "op1",
+		Username:  "alice",
+		Command:   "set-entry",
+	})
+	ctx := context.Background()
+
+	_, err := client.SetEntry(ctx, &gslbsvc.SetEntryRequest{
+		Entry: &entries.Entry{
+			Fqdn:        "a.example.com.",
+			MembersIpv4: []*entries.Member{{Ip: "10.0.0.1", Dc: "dc1"}},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = client.SetMembersStatus(ctx, &gslbsvc.SetMembersStatusRequest{Dc: "dc1", DryRun: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = client.SetMembersStatus(ctx, &gslbsvc.SetMembersStatusRequest{Dc: "dc1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = client.DeleteMember(ctx, &gslbsvc.DeleteMemberRequest{Fqdn: "a.example.com.", Ip: "10.0.0.9"})
+	if err == nil {
+		t.Fatal("Expected error when deleting unknown member")
+	}
+
+	records, err := journal.Records()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 3 {
+		t.Fatalf("Expected 3 records, dry run must not be recorded, but got %d", len(records))
+	}
+	if records[0].Method != "SetEntry" || len(records[0].Prior) != 0 || records[0].Username != "alice" || records[0].Operation != "op1" {
+		t.Errorf("Unexpected record for new entry %+v", records[0])
+	}
+
+	if records[1].Method != "SetMembersStatus" || len(records[1].Objects) != 1 || records[1].Objects[0] != "a.example.com. 10.0.0.1" {
+		t.Errorf("Unexpected record for members status %+v", records[1])
+	}
+	priors := make([]*gslbsvc.SetMemberRequest, 0)
+	for _, raw := range jsonList(t, records[1].Prior) {
+		prior, _, err := BytesToProto[*gslbsvc.SetMemberRequest](raw, "prior.json")
+		if err != nil {
+			t.Fatal(err)
+		}
+		priors = append(priors, prior)
+	}
+	if len(priors) != 1 || priors[0].GetMember().GetDc() != "dc1" {
+		t.Errorf("Expected prior state of updated member but got %v", priors)
+	}
+
+	if records[2].Result != app.AuditResultError || records[2].Error == "" {
+		t.Errorf("Expected failed record but got %+v", records[2])
+	}
+
+	h := &History{Ip: "10.0.0.1"}
+	if filtered := h.Filter(records, time.Now()); len(filtered) != 1 || filtered[0].Method != "SetMembersStatus" {
+		t.Errorf("Expected only members status record for ip but got %v", filtered)
+	}
+	h = &History{Failed: true}
+	if filtered := h.Filter(records, time.Now()); len(filtered) != 1 || filtered[0].Method != "DeleteMember" {
+		t.Errorf("Expected only failed record but got %v", filtered)
+	}
+	h = &History{Limit: 2}
+	if filtered := h.Filter(records, time.Now()); len(filtered) != 2 || filtered[0].Method != "SetMembersStatus" {
+		t.Errorf("Expected 2 most recent records but got %v", filtered)
+	}
+	h = &History{Since: time.Hour}
+	if filtered := h.Filter(records, time.Now().Add(2*time.Hour)); len(filtered) != 0 {
+		t.Errorf("Expected no records since an hour but got %v", filtered)
+	}
+}
+
+func jsonList(t *testing.T, raw json.RawMessage) []json.RawMessage {
+	list := make([]json.RawMessage, 0)
+	err := json.Unmarshal(raw, &list)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return list
+}
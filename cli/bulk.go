@@ -0,0 +1,84 @@
+package cli
+
+import (
+	"fmt"
+	msg "github.com/ArthurHlt/messages"
+	"github.com/orange-cloudfoundry/gsloc-go-sdk/gsloc/api/config/entries/v1"
+	gslbsvc "github.com/orange-cloudfoundry/gsloc-go-sdk/gsloc/services/gslb/v1"
+	"sync"
+)
+
+// BulkResult is the result of deleting one object in a bulk deletion.
+type BulkResult struct {
+	Name    string
+	TrashId string
+	Err     error
+}
+
+// RunConcurrently call action for each index from 0 to n-1 with at most concurrency calls running at the same time.
+func RunConcurrently(n, concurrency int, action func(i int)) {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	sem := make(chan struct{}, concurrency)
+	wg := &sync.WaitGroup{}
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			action(i)
+		}(i)
+	}
+	wg.Wait()
+}
+
+// PrintBulkResults show result of each deletion and give an error if one of them failed.
+func PrintBulkResults(header string, results []*BulkResult) error {
+	table := MakeTableWriter([]string{header, "Result"})
+	table.SetAutoWrapText(false)
+	nbFailed := 0
+	for _, result := range results {
+		if result.Err != nil {
+			nbFailed++
+			table.Append([]string{result.Name, msg.Red("failed: " + result.Err.Error()).String()})
+			continue
+		}
+		table.Append([]string{result.Name, msg.Green("deleted").String() + ", restore id " + result.TrashId})
+	}
+	table.Render()
+	if nbFailed > 0 {
+		return fmt.Errorf("%d on %d deletions failed", nbFailed, len(results))
+	}
+	msg.Successf("%d deleted successfully.", len(results))
+	return nil
+}
+
+// MembersToDelete gives members of entries matching ip and dc, empty ip or dc matches everything.
+// Members are grouped by entry to not update the same entry concurrently.
+func MembersToDelete(ents []*entries.Entry, ip, dc string) [][]*gslbsvc.SetMemberRequest {
+	groups := make([][]*gslbsvc.SetMemberRequest, 0)
+	for _, ent := range ents {
+		members := make([]*entries.Member, 0, len(ent.GetMembersIpv4())+len(ent.GetMembersIpv6()))
+		members = append(members, ent.GetMembersIpv4()...)
+		members = append(members, ent.GetMembersIpv6()...)
+
+		group := make([]*gslbsvc.SetMemberRequest, 0)
+		for _, member := range members {
+			if (ip != "" && member.GetIp() != ip) || (dc != "" && member.GetDc() != dc) {
+				continue
+			}
+			group = append(group, &gslbsvc.SetMemberRequest{
+				Fqdn:   ent.GetFqdn(),
+				Member: member,
+			})
+		}
+		if len(group) > 0 {
+			groups = append(groups, group)
+		}
+	}
+	return groups
+}
@@ -0,0 +1,81 @@
+package cli
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/orange-cloudfoundry/gsloc-go-sdk/gsloc/api/config/entries/v1"
+)
+
+func TestRunConcurrently(t *testing.T) {
+	mu := &sync.Mutex{}
+	running, maxRunning := 0, 0
+	done := make([]bool, 20)
+	RunConcurrently(len(done), 3, func(i int) {
+		mu.Lock()
+		running++
+		if running > maxRunning {
+			maxRunning = running
+		}
+		mu.Unlock()
+
+		done[i] = true
+
+		mu.Lock()
+		running--
+		mu.Unlock()
+	})
+	if maxRunning > 3 {
+		t.Errorf("Expected at most 3 concurrent calls but got %d", maxRunning)
+	}
+	for i, ok := range done {
+		if !ok {
+			t.Errorf("Expected action to be called for index %d", i)
+		}
+	}
+}
+
+func TestMembersToDelete(t *testing.T) {
+	ents := []*entries.Entry{
+		{
+			Fqdn: "a.example.com.",
+			MembersIpv4: []*entries.Member{
+				{Ip: "10.0.0.1", Dc: "dc1"},
+				{Ip: "10.0.0.2", Dc: "dc2"},
+			},
+			MembersIpv6: []*entries.Member{
+				{Ip: "2001:db8::1", Dc: "dc1"},
+			},
+		},
+		{
+			Fqdn: "b.example.com.",
+			MembersIpv4: []*entries.Member{
+				{Ip: "10.0.0.1", Dc: "dc1"},
+			},
+		},
+		{
+			Fqdn: "c.example.com.",
+			MembersIpv4: []*entries.Member{
+				{Ip: "10.0.0.3", Dc: "dc2"},
+			},
+		},
+	}
+
+	groups := MembersToDelete(ents, "", "dc1")
+	if len(groups) != 2 || len(groups[0]) != 2 || len(groups[1]) != 1 {
+		t.Fatalf("Expected members of dc1 grouped by entry but got %v", groups)
+	}
+	if groups[0][1].GetMember().GetIp() != "2001:db8::1" || groups[1][0].GetFqdn() != "b.example.com." {
+		t.Errorf("Unexpected members %v", groups)
+	}
+
+	groups = MembersToDelete(ents, "10.0.0.1", "dc1")
+	if len(groups) != 2 || len(groups[0]) != 1 || len(groups[1]) != 1 {
+		t.Errorf("Expected one member on two entries but got %v", groups)
+	}
+
+	groups = MembersToDelete(ents, "10.0.0.9", "")
+	if len(groups) != 0 {
+		t.Errorf("Expected no members but got %v", groups)
+	}
+}
@@ -10,7 +10,6 @@ import (
 	"github.com/homeport/dyff/pkg/dyff"
 	"github.com/olekukonko/tablewriter"
 	"github.com/orange-cloudfoundry/gsloc-cli/highlight"
-	hcconf "github.com/orange-cloudfoundry/gsloc-go-sdk/gsloc/api/config/healthchecks/v1"
 	"github.com/orange-cloudfoundry/gsloc-go-sdk/helpers"
 	"google.golang.org/protobuf/encoding/protojson"
 	"google.golang.org/protobuf/proto"
@@ -19,12 +18,14 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"reflect"
 	"regexp"
 	kyaml "sigs.k8s.io/yaml"
 	"strings"
+	"text/template"
 )
 
-var emptyJsonRegex = regexp.MustCompile(`\{\s*\}`)
+var emptyJsonRegex = regexp.MustCompile(`^\s*\{\s*\}\s*$`)
 
 //var revertEnumType = map[string]string{
 //	helpers.TypeUrl[*cert.Certificate]():             "certificate",
@@ -49,12 +50,18 @@ func FileToProto[T proto.Message](file string) (protoMsg T, loaded bool, err err
 		}
 		return protoMsg, false, fmt.Errorf("failed to read file %s: %w", file, err)
 	}
+	return BytesToProto[T](content, file)
+}
+
+// BytesToProto unmarshal content in json or in yaml if name has a yaml extension.
+func BytesToProto[T proto.Message](content []byte, name string) (protoMsg T, loaded bool, err error) {
+	var protoMsgDef T
+	protoMsg = protoMsgDef.ProtoReflect().New().Interface().(T)
 
-	ext := strings.ToLower(filepath.Ext(file))
-	if ext == ".yaml" || ext == ".yml" {
+	if IsYamlFile(name) {
 		content, err = kyaml.YAMLToJSON(content)
 		if err != nil {
-			return protoMsg, false, fmt.Errorf("failed to convert yaml to json file %s: %w", file, err)
+			return protoMsg, false, fmt.Errorf("failed to convert yaml to json file %s: %w", name, err)
 		}
 	}
 
@@ -68,11 +75,16 @@ func FileToProto[T proto.Message](file string) (protoMsg T, loaded bool, err err
 
 	err = protojson.Unmarshal(content, protoMsg)
 	if err != nil {
-		return protoMsg, false, fmt.Errorf("failed to unmarshal json file %s: %w", file, err)
+		return protoMsg, false, fmt.Errorf("failed to unmarshal json file %s: %w", name, err)
 	}
 	return protoMsg, true, nil
 }
 
+func IsYamlFile(name string) bool {
+	ext := strings.ToLower(filepath.Ext(name))
+	return ext == ".yaml" || ext == ".yml"
+}
+
 func ProtoToYaml(pMsg proto.Message) ([]byte, error) {
 	data, err := protojson.MarshalOptions{
 		Multiline:       true,
@@ -91,28 +103,6 @@ func ProtoToYaml(pMsg proto.Message) ([]byte, error) {
 	return yaml.Marshal(mapProto)
 }
 
-func MakePayloadFromString(txt string) (*hcconf.HealthCheckPayload, error) {
-	if txt == "" {
-		return nil, nil
-	}
-	if txt[0] == '@' {
-		b, err := os.ReadFile(txt[1:])
-		if err != nil {
-			return nil, err
-		}
-		return &hcconf.HealthCheckPayload{
-			Payload: &hcconf.HealthCheckPayload_Binary{
-				Binary: b,
-			},
-		}, nil
-	}
-	return &hcconf.HealthCheckPayload{
-		Payload: &hcconf.HealthCheckPayload_Text{
-			Text: txt,
-		},
-	}, nil
-}
-
 func NameFromAny(anyMsg *anypb.Any) string {
 	data, err := protojson.MarshalOptions{
 		Multiline:       true,
@@ -183,15 +173,11 @@ func PrintProtoDiff(from, dest proto.Message) error {
 	if err != nil {
 		return fmt.Errorf("failed to compare input files: %s", err.Error())
 	}
-
-	reportWriter := &dyff.HumanReport{
-		Report:               report,
-		NoTableStyle:         true,
-		OmitHeader:           true,
-		UseGoPatchPaths:      false,
-		MinorChangeThreshold: 0.1,
+	name := helpers.GetIdentifier(dest)
+	if isEmptyProto(dest) {
+		name = helpers.GetIdentifier(from)
 	}
-	return reportWriter.WriteReport(msg.Output())
+	return WriteProtoDiff(msg.Output(), name, from, dest, report, opts.DiffFormat)
 }
 
 func ProtoDiffContent(from, dest proto.Message) (string, error) {
@@ -221,12 +207,30 @@ func PrintProtoHuman(pMsg proto.Message) error {
 	if err != nil {
 		return fmt.Errorf("failed to convert proto to yaml: %w", err)
 	}
+	return printYaml(msg.Output(), dataYml)
+}
+
+// printYaml write yaml highlighted only when written to a terminal, so it can be piped to other tools.
+func printYaml(w io.Writer, dataYml []byte) error {
+	if f, ok := w.(*os.File); !ok || !IsTerminal(f) {
+		_, err := w.Write(dataYml)
+		return err
+	}
 	result, err := highlight.Highlight(bytes.NewBuffer(dataYml))
 	if err != nil {
 		return fmt.Errorf("failed to highlight yaml: %w", err)
 	}
-	fmt.Println(result)
-	return nil
+	_, err = fmt.Fprintln(w, result)
+	return err
+}
+
+// IsTerminal tells if file is a terminal and not a pipe or a regular file.
+func IsTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
 }
 
 func PrintProtoJson(pMsg proto.Message) error {
@@ -257,6 +261,146 @@ func PrintProtoListJson[T proto.Message](msgs []T) error {
 	return nil
 }
 
+const (
+	OutputTable = "table"
+	OutputWide  = "wide"
+	OutputJson  = "json"
+	OutputYaml  = "yaml"
+	OutputName  = "name"
+
+	OutputJsonPathPrefix   = "jsonpath="
+	OutputGoTemplatePrefix = "go-template="
+)
+
+// OutputFormat must be embedded in commands printing results to give them the --output option.
+type OutputFormat struct {
+	Output string `short:"o" long:"output" description:"Output format, one of: table, wide, json, yaml, name, jsonpath=<path>, go-template=<template>." default:"table"`
+	Json   bool   `short:"j" long:"json" description:"Format in json instead of human table readable (same as --output=json)."`
+}
+
+func (o OutputFormat) Format() string {
+	if o.Json {
+		return OutputJson
+	}
+	if o.Output == "" {
+		return OutputTable
+	}
+	return o.Output
+}
+
+// IsHuman tells if output is meant to be read by humans, informative messages can be printed only in this case.
+func (o OutputFormat) IsHuman() bool {
+	format := o.Format()
+	return format == OutputTable || format == OutputWide
+}
+
+// Print write data in requested format, data is a proto message, a slice of proto messages or
+// any value which can be marshaled in json. Names are used by name format and table is called
+// for table and wide formats.
+func (o OutputFormat) Print(data any, names []string, table func(wide bool) error) error {
+	format := o.Format()
+	w := msg.Output()
+	switch {
+	case format == OutputTable || format == OutputWide:
+		return table(format == OutputWide)
+	case format == OutputJson:
+		return printOutputJson(data)
+	case format == OutputName:
+		for _, name := range names {
+			_, err := fmt.Fprintln(w, name)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	generic, err := ToGeneric(data)
+	if err != nil {
+		return err
+	}
+	switch {
+	case format == OutputYaml:
+		dataYml, err := yaml.Marshal(generic)
+		if err != nil {
+			return err
+		}
+		return printYaml(w, dataYml)
+	case strings.HasPrefix(format, OutputJsonPathPrefix):
+		jsonPath, err := ParseJsonPath(strings.TrimPrefix(format, OutputJsonPathPrefix))
+		if err != nil {
+			return err
+		}
+		err = jsonPath.Execute(w, generic)
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(w)
+		return err
+	case strings.HasPrefix(format, OutputGoTemplatePrefix):
+		tpl, err := template.New("output").Parse(strings.TrimPrefix(format, OutputGoTemplatePrefix))
+		if err != nil {
+			return fmt.Errorf("invalid go-template: %w", err)
+		}
+		err = tpl.Execute(w, generic)
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(w)
+		return err
+	}
+	return fmt.Errorf("unknown output format %s", format)
+}
+
+func printOutputJson(data any) error {
+	if pMsg, ok := data.(proto.Message); ok {
+		return PrintProtoJson(pMsg)
+	}
+	if pMsgs, ok := protoSlice(data); ok {
+		return PrintProtoListJson[proto.Message](pMsgs)
+	}
+	return writeJson(msg.Output(), data)
+}
+
+// ToGeneric convert data to maps, slices and scalars as seen in json,
+// proto messages use proto field names.
+func ToGeneric(data any) (any, error) {
+	var b []byte
+	var err error
+	marshalOpts := protojson.MarshalOptions{
+		UseProtoNames:   true,
+		EmitUnpopulated: true,
+	}
+	if pMsg, ok := data.(proto.Message); ok {
+		b, err = marshalOpts.Marshal(pMsg)
+	} else if pMsgs, ok := protoSlice(data); ok {
+		b, err = helpers.MarshalListProtoMessage[proto.Message](marshalOpts, pMsgs)
+	} else {
+		b, err = json.Marshal(data)
+	}
+	if err != nil {
+		return nil, err
+	}
+	var generic any
+	err = json.Unmarshal(b, &generic)
+	if err != nil {
+		return nil, err
+	}
+	return generic, nil
+}
+
+func protoSlice(data any) ([]proto.Message, bool) {
+	val := reflect.ValueOf(data)
+	if val.Kind() != reflect.Slice || !val.Type().Elem().Implements(reflect.TypeOf((*proto.Message)(nil)).Elem()) {
+		return nil, false
+	}
+	pMsgs := make([]proto.Message, val.Len())
+	for i := range pMsgs {
+		pMsgs[i] = val.Index(i).Interface().(proto.Message)
+	}
+	return pMsgs, true
+}
+
 func MakeTableWriter(headers []string, writers ...io.Writer) *tablewriter.Table {
 	writer := msg.Output()
 	if len(writers) > 0 {
@@ -270,12 +414,27 @@ func MakeTableWriter(headers []string, writers ...io.Writer) *tablewriter.Table
 }
 
 func DiffAndConfirm(from, dest proto.Message, force bool) (bool, error) {
-	msg.Info("Change to be made:")
-	msg.Printf("━━━━━\n")
+	if !IsMachineDiffFormat(opts.DiffFormat) {
+		msg.Info("Change to be made:")
+		msg.Printf("━━━━━\n")
+	}
 	err := PrintProtoDiff(from, dest)
 	if err != nil {
 		return false, err
 	}
+	return Confirm(force)
+}
+
+// DryRunDiff show change which would be made without asking for confirmation.
+func DryRunDiff(from, dest proto.Message) error {
+	if !IsMachineDiffFormat(opts.DiffFormat) {
+		msg.Info("Dry run, change which would be made:")
+		msg.Printf("━━━━━\n")
+	}
+	return PrintProtoDiff(from, dest)
+}
+
+func Confirm(force bool) (bool, error) {
 	if force {
 		return true, nil
 	}
@@ -283,7 +442,8 @@ func DiffAndConfirm(from, dest proto.Message, force bool) (bool, error) {
 	prompt := &survey.Confirm{
 		Message: "Do you confirm theses changes?",
 	}
-	err = survey.AskOne(prompt, &confirm)
+	// prompt is written on stderr to not mix it with results on stdout
+	err := survey.AskOne(prompt, &confirm, survey.WithStdio(os.Stdin, os.Stderr, os.Stderr))
 	if err != nil {
 		return false, err
 	}
@@ -0,0 +1,18 @@
+package cli
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestPrintYamlNotTerminal(t *testing.T) {
+	dataYml := []byte("fqdn: a.example.com.\nttl: 30\n")
+	buf := &bytes.Buffer{}
+	err := printYaml(buf, dataYml)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != string(dataYml) {
+		t.Errorf("Expected yaml to be written as is but got %q", buf.String())
+	}
+}
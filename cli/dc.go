@@ -0,0 +1,380 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/ArthurHlt/go-flags"
+	msg "github.com/ArthurHlt/messages"
+	"github.com/orange-cloudfoundry/gsloc-cli/app"
+	"github.com/orange-cloudfoundry/gsloc-go-sdk/gsloc/api/config/entries/v1"
+	gslbsvc "github.com/orange-cloudfoundry/gsloc-go-sdk/gsloc/services/gslb/v1"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+type DcName struct {
+	content string
+}
+
+func (n *DcName) String() string {
+	return n.content
+}
+
+func (n *DcName) Complete(match string) []flags.Completion {
+	opts.ConfigPath = defaultConfigPath
+	if os.Getenv("GSLOC_CONFIG_PATH") != "" {
+		opts.ConfigPath = os.Getenv("GSLOC_CONFIG_PATH")
+	}
+	opts.Target = os.Getenv("GSLOC_TARGET")
+
+	clientConn, err := app.CreateConnFromFile(ExpandConfigPath(), opts.Target)
+	if err != nil {
+		return []flags.Completion{
+			{Item: "Error: " + err.Error()},
+		}
+	}
+	defer clientConn.Close() // nolint:errcheck
+
+	resp, err := app.MakeClient(clientConn).ListDcs(context.Background(), &gslbsvc.ListDcsRequest{})
+	if err != nil {
+		return []flags.Completion{
+			{Item: "Error: " + err.Error()},
+		}
+	}
+	completions := make([]flags.Completion, 0)
+	for _, dc := range resp.GetDcs() {
+		if strings.HasPrefix(dc, match) {
+			completions = append(completions, flags.Completion{Item: dc})
+		}
+	}
+	return completions
+}
+
+func (n *DcName) UnmarshalFlag(value string) error {
+	n.content = value
+	return nil
+}
+
+// DrainSnapshot is saved when draining a datacenter, it contains state of members before drain.
+type DrainSnapshot struct {
+	Target    string         `json:"target"`
+	Dc        string         `json:"dc"`
+	CreatedAt time.Time      `json:"created_at"`
+	Members   []*DrainMember `json:"members"`
+}
+
+type DrainMember struct {
+	Fqdn     string `json:"fqdn"`
+	Ip       string `json:"ip"`
+	Disabled bool   `json:"disabled"`
+}
+
+type DcCmd struct{}
+
+type DrainDc struct {
+	Name *DcName `positional-args:"true" positional-arg-name:"'dc'" required:"true"`
+
+	Tags         []string       `short:"t" long:"tag" description:"Only drain entries with tag(s) (can be set multiple times)."`
+	Prefix       string         `short:"p" long:"prefix" description:"Only drain entries with prefix."`
+	StateFile    flags.Filename `long:"state-file" description:"File where drained members are saved, default to drains/<target>_<dc>.json next to config file."`
+	IgnoreHealth bool           `long:"ignore-health" description:"Drain even if some entries would have no online member left in other datacenters."`
+	Force        bool           `long:"force" description:"Drain without confirmation"`
+
+	client gslbsvc.GSLBClient
+}
+
+type RestoreDc struct {
+	Name *DcName `positional-args:"true" positional-arg-name:"'dc'" required:"true"`
+
+	StateFile flags.Filename `long:"state-file" description:"File where drained members have been saved, default to drains/<target>_<dc>.json next to config file."`
+	Force     bool           `long:"force" description:"Restore without confirmation"`
+
+	client gslbsvc.GSLBClient
+}
+
+var dcCmd DcCmd
+var drainDc DrainDc
+var restoreDc RestoreDc
+
+func (c *DrainDc) SetClient(client gslbsvc.GSLBClient) {
+	c.client = client
+}
+
+func (c *RestoreDc) SetClient(client gslbsvc.GSLBClient) {
+	c.client = client
+}
+
+func (c *DrainDc) Execute([]string) error {
+	dc := c.Name.String()
+	targetName, stateFile, err := drainStateFile(string(c.StateFile), dc)
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(stateFile); err == nil {
+		return fmt.Errorf("datacenter %s is already drained (state in %s), restore it first", dc, stateFile)
+	}
+
+	entsResp, err := c.client.ListEntries(context.Background(), &gslbsvc.ListEntriesRequest{
+		Tags:   c.Tags,
+		Prefix: c.Prefix,
+	})
+	if err != nil {
+		return err
+	}
+	statusResp, err := c.client.ListEntriesStatus(context.Background(), &gslbsvc.ListEntriesStatusRequest{
+		Tags:   c.Tags,
+		Prefix: c.Prefix,
+	})
+	if err != nil {
+		return err
+	}
+
+	members, unsafeFqdns := DrainPlan(dc, entsResp.GetEntries(), statusResp.GetEntriesStatus())
+	if len(members) == 0 {
+		msg.Infof("No enabled members found in datacenter %s.", msg.Cyan(dc))
+		return nil
+	}
+	if len(unsafeFqdns) > 0 {
+		msg.Warning("Those entries would have no online member left in other datacenters:")
+		for _, fqdn := range unsafeFqdns {
+			msg.Printf("  - %s\n", fqdn)
+		}
+		if !c.IgnoreHealth {
+			return fmt.Errorf("%d entries would be unavailable, use --ignore-health to drain anyway", len(unsafeFqdns))
+		}
+	}
+
+	msg.Infof("Members of datacenter %s to disable:", msg.Cyan(dc))
+	printDrainMembers(members)
+	confirm, err := Confirm(c.Force)
+	if err != nil {
+		return err
+	}
+	if !confirm {
+		return nil
+	}
+
+	err = writeDrainSnapshot(stateFile, &DrainSnapshot{
+		Target:    targetName,
+		Dc:        dc,
+		CreatedAt: time.Now(),
+		Members:   members,
+	})
+	if err != nil {
+		return err
+	}
+	msg.Infof("Members state saved in %s.", stateFile)
+
+	failed := setMembersDisabled(c.client, members, true)
+	if len(failed) > 0 {
+		return fmt.Errorf("%d on %d members failed to be disabled", len(failed), len(members))
+	}
+	msg.Successf("%d members of datacenter %s disabled, use %s to enable them back.",
+		len(members), msg.Cyan(dc), msg.Cyan("dc restore "+dc))
+	return nil
+}
+
+func (c *RestoreDc) Execute([]string) error {
+	dc := c.Name.String()
+	targetName, stateFile, err := drainStateFile(string(c.StateFile), dc)
+	if err != nil {
+		return err
+	}
+	snapshot, err := readDrainSnapshot(stateFile)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return fmt.Errorf("no drain found for datacenter %s in %s", dc, stateFile)
+		}
+		return err
+	}
+	if snapshot.Target != targetName || snapshot.Dc != dc {
+		return fmt.Errorf("state file %s has been made for datacenter %s on target %s", stateFile, snapshot.Dc, snapshot.Target)
+	}
+
+	msg.Infof("Members of datacenter %s drained at %s to restore:", msg.Cyan(dc), snapshot.CreatedAt.Format(time.RFC3339))
+	printDrainMembers(snapshot.Members)
+	confirm, err := Confirm(c.Force)
+	if err != nil {
+		return err
+	}
+	if !confirm {
+		return nil
+	}
+
+	failed := make([]*DrainMember, 0)
+	for _, member := range snapshot.Members {
+		failed = append(failed, setMembersDisabled(c.client, []*DrainMember{member}, member.Disabled)...)
+	}
+	if len(failed) > 0 {
+		snapshot.Members = failed
+		err = writeDrainSnapshot(stateFile, snapshot)
+		if err != nil {
+			return err
+		}
+		return fmt.Errorf("%d members failed to be restored, they are kept in %s to retry", len(failed), stateFile)
+	}
+	err = os.Remove(stateFile)
+	if err != nil {
+		return err
+	}
+	msg.Successf("Datacenter %s restored.", msg.Cyan(dc))
+	return nil
+}
+
+// DrainPlan gives enabled members in datacenter and entries which would have no online member left in other datacenters.
+func DrainPlan(dc string, ents []*gslbsvc.GetEntryResponse, statuses []*gslbsvc.GetEntryStatusResponse) ([]*DrainMember, []string) {
+	onlineElsewhere := make(map[string]bool)
+	for _, entStatus := range statuses {
+		for _, member := range entryMembersStatus(entStatus) {
+			if member.GetDc() != dc && member.GetStatus() == gslbsvc.MemberStatus_ONLINE {
+				onlineElsewhere[entStatus.GetFqdn()] = true
+			}
+		}
+	}
+
+	members := make([]*DrainMember, 0)
+	unsafeFqdns := make([]string, 0)
+	for _, ent := range ents {
+		fqdn := ent.GetEntry().GetFqdn()
+		nbMembers := 0
+		for _, member := range append(append([]*entries.Member{}, ent.GetEntry().GetMembersIpv4()...), ent.GetEntry().GetMembersIpv6()...) {
+			if member.GetDc() != dc || member.GetDisabled() {
+				continue
+			}
+			nbMembers++
+			members = append(members, &DrainMember{
+				Fqdn:     fqdn,
+				Ip:       member.GetIp(),
+				Disabled: member.GetDisabled(),
+			})
+		}
+		if nbMembers > 0 && !onlineElsewhere[fqdn] {
+			unsafeFqdns = append(unsafeFqdns, fqdn)
+		}
+	}
+	sort.Strings(unsafeFqdns)
+	return members, unsafeFqdns
+}
+
+// setMembersDisabled update disabled flag of members one by one, members which failed are given back.
+func setMembersDisabled(client gslbsvc.GSLBClient, members []*DrainMember, disabled bool) []*DrainMember {
+	failed := make([]*DrainMember, 0)
+	for _, member := range members {
+		resp, err := client.GetMember(context.Background(), &gslbsvc.GetMemberRequest{
+			Fqdn: member.Fqdn,
+			Ip:   member.Ip,
+		})
+		if err != nil && strings.Contains(err.Error(), "not found") {
+			msg.Warning(fmt.Sprintf("Member %s of %s does not exist anymore, skipped.", member.Ip, member.Fqdn))
+			continue
+		}
+		if err == nil {
+			resp.GetMember().Disabled = disabled
+			_, err = client.SetMember(context.Background(), &gslbsvc.SetMemberRequest{
+				Fqdn:   member.Fqdn,
+				Member: resp.GetMember(),
+			})
+		}
+		if err != nil {
+			msg.Error(fmt.Sprintf("Failed to update member %s of %s: %s", member.Ip, member.Fqdn, err.Error()))
+			failed = append(failed, member)
+			continue
+		}
+		state := msg.Green("enabled").String()
+		if disabled {
+			state = msg.Red("disabled").String()
+		}
+		msg.Successf("Member %s of %s %s.", msg.Cyan(member.Ip), msg.Cyan(member.Fqdn), state)
+	}
+	return failed
+}
+
+func printDrainMembers(members []*DrainMember) {
+	ipsByFqdn := make(map[string][]string)
+	fqdns := make([]string, 0)
+	for _, member := range members {
+		if _, ok := ipsByFqdn[member.Fqdn]; !ok {
+			fqdns = append(fqdns, member.Fqdn)
+		}
+		ipsByFqdn[member.Fqdn] = append(ipsByFqdn[member.Fqdn], member.Ip)
+	}
+	table := MakeTableWriter([]string{"FQDN", "IPs"})
+	table.SetAutoWrapText(false)
+	for _, fqdn := range fqdns {
+		table.Append([]string{fqdn, strings.Join(ipsByFqdn[fqdn], "\n")})
+	}
+	table.Render()
+}
+
+// drainStateFile gives current target name and state file path, default path depends on target and datacenter.
+func drainStateFile(stateFile, dc string) (string, string, error) {
+	target, err := app.GetTarget(ExpandConfigPath(), opts.Target)
+	if err != nil {
+		return "", "", err
+	}
+	if stateFile == "" {
+		stateFile = filepath.Join(filepath.Dir(ExpandConfigPath()), "drains", fmt.Sprintf("%s_%s.json", target.Name, dc))
+	}
+	return target.Name, stateFile, nil
+}
+
+func writeDrainSnapshot(stateFile string, snapshot *DrainSnapshot) error {
+	err := os.MkdirAll(filepath.Dir(stateFile), 0700)
+	if err != nil {
+		return err
+	}
+	b, err := json.MarshalIndent(snapshot, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(stateFile, b, 0600)
+}
+
+func readDrainSnapshot(stateFile string) (*DrainSnapshot, error) {
+	b, err := os.ReadFile(stateFile)
+	if err != nil {
+		return nil, err
+	}
+	snapshot := &DrainSnapshot{}
+	err = json.Unmarshal(b, snapshot)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read state file %s: %w", stateFile, err)
+	}
+	return snapshot, nil
+}
+
+func init() {
+	desc := "Put a datacenter in maintenance and restore it."
+	cmd, err := parser.AddCommand(
+		"dc",
+		desc,
+		desc,
+		&dcCmd)
+	if err != nil {
+		panic(err)
+	}
+
+	desc = "Disable every enabled member of a datacenter after checking entries stay available, members state is saved to be restored."
+	_, err = cmd.AddCommand(
+		"drain",
+		desc,
+		desc,
+		&drainDc)
+	if err != nil {
+		panic(err)
+	}
+
+	desc = "Restore members of a drained datacenter in the state they were before drain."
+	_, err = cmd.AddCommand(
+		"restore",
+		desc,
+		desc,
+		&restoreDc)
+	if err != nil {
+		panic(err)
+	}
+}
@@ -0,0 +1,63 @@
+package cli
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/orange-cloudfoundry/gsloc-go-sdk/gsloc/api/config/entries/v1"
+	gslbsvc "github.com/orange-cloudfoundry/gsloc-go-sdk/gsloc/services/gslb/v1"
+)
+
+func TestDrainPlan(t *testing.T) {
+	ents := []*gslbsvc.GetEntryResponse{
+		{Entry: &entries.Entry{
+			Fqdn: "a.example.com.",
+			MembersIpv4: []*entries.Member{
+				{Ip: "10.0.0.1", Dc: "dc1"},
+				{Ip: "10.0.0.2", Dc: "dc1", Disabled: true},
+				{Ip: "10.0.0.3", Dc: "dc2"},
+			},
+		}},
+		{Entry: &entries.Entry{
+			Fqdn: "b.example.com.",
+			MembersIpv4: []*entries.Member{
+				{Ip: "10.0.1.1", Dc: "dc1"},
+				{Ip: "10.0.1.2", Dc: "dc2"},
+			},
+		}},
+		{Entry: &entries.Entry{
+			Fqdn: "c.example.com.",
+			MembersIpv4: []*entries.Member{
+				{Ip: "10.0.2.1", Dc: "dc2"},
+			},
+		}},
+	}
+	statuses := []*gslbsvc.GetEntryStatusResponse{
+		{
+			Fqdn: "a.example.com.",
+			MembersIpv4: []*gslbsvc.MemberStatus{
+				{Ip: "10.0.0.1", Dc: "dc1", Status: gslbsvc.MemberStatus_ONLINE},
+				{Ip: "10.0.0.3", Dc: "dc2", Status: gslbsvc.MemberStatus_ONLINE},
+			},
+		},
+		{
+			Fqdn: "b.example.com.",
+			MembersIpv4: []*gslbsvc.MemberStatus{
+				{Ip: "10.0.1.1", Dc: "dc1", Status: gslbsvc.MemberStatus_ONLINE},
+				{Ip: "10.0.1.2", Dc: "dc2", Status: gslbsvc.MemberStatus_CHECK_FAILED},
+			},
+		},
+	}
+
+	members, unsafeFqdns := DrainPlan("dc1", ents, statuses)
+	expected := []*DrainMember{
+		{Fqdn: "a.example.com.", Ip: "10.0.0.1"},
+		{Fqdn: "b.example.com.", Ip: "10.0.1.1"},
+	}
+	if !reflect.DeepEqual(members, expected) {
+		t.Errorf("Expected members %v but got %v", expected, members)
+	}
+	if !reflect.DeepEqual(unsafeFqdns, []string{"b.example.com."}) {
+		t.Errorf("Expected b.example.com. to be unsafe but got %v", unsafeFqdns)
+	}
+}
@@ -2,12 +2,22 @@ package cli
 
 import (
 	"context"
+	"fmt"
 	msg "github.com/ArthurHlt/messages"
 	gslbsvc "github.com/orange-cloudfoundry/gsloc-go-sdk/gsloc/services/gslb/v1"
+	"strconv"
+	"strings"
 )
 
 type DeleteEntry struct {
-	FQDN *FQDN `positional-args:"true" positional-arg-name:"'fqdn'" required:"true"`
+	FQDN *FQDN `positional-args:"true" positional-arg-name:"'fqdn'"`
+
+	Tags        []string `short:"t" long:"tag" description:"Delete all entries with tag(s) when no fqdn is given (can be set multiple times)"`
+	Prefix      string   `short:"p" long:"prefix" description:"Delete all entries with prefix when no fqdn is given"`
+	Concurrency int      `long:"concurrency" description:"Number of entries deleted at the same time when deleting multiple entries" default:"5"`
+
+	DryRun bool `long:"dry-run" description:"Only show entries which would be deleted"`
+	Force  bool `long:"force" description:"Delete entries without confirmation"`
 
 	client gslbsvc.GSLBClient
 }
@@ -19,18 +29,109 @@ func (c *DeleteEntry) SetClient(client gslbsvc.GSLBClient) {
 var deleteEntry DeleteEntry
 
 func (c *DeleteEntry) Execute([]string) error {
-	_, err := c.client.DeleteEntry(context.Background(), &gslbsvc.DeleteEntryRequest{
+	bulk := len(c.Tags) > 0 || c.Prefix != ""
+	if !c.FQDN.IsEmpty() && bulk {
+		return fmt.Errorf("fqdn can't be used with --tag or --prefix")
+	}
+	if bulk {
+		return c.executeBulk()
+	}
+	if c.FQDN.IsEmpty() {
+		return fmt.Errorf("a fqdn or one of --tag or --prefix must be set")
+	}
+
+	entResp, err := c.client.GetEntry(context.Background(), &gslbsvc.GetEntryRequest{
 		Fqdn: c.FQDN.String(),
 	})
 	if err != nil {
 		return err
 	}
-	msg.Successf("Entry %s deleted", msg.Cyan(c.FQDN))
+	previousEntry := &gslbsvc.SetEntryRequest{
+		Entry:       entResp.GetEntry(),
+		Healthcheck: entResp.GetHealthcheck(),
+	}
+	if c.DryRun {
+		return DryRunDiff(previousEntry, nil)
+	}
+	confirm, err := DiffAndConfirm(previousEntry, nil, c.Force)
+	if err != nil {
+		return err
+	}
+	if !confirm {
+		return nil
+	}
+	item, err := c.delete(previousEntry)
+	if err != nil {
+		return err
+	}
+	msg.Successf("Entry %s deleted, use %s to undo.", msg.Cyan(c.FQDN), msg.Yellow("restore "+item.Id))
 	return nil
 }
 
+func (c *DeleteEntry) executeBulk() error {
+	entsResp, err := c.client.ListEntries(context.Background(), &gslbsvc.ListEntriesRequest{
+		Tags:   c.Tags,
+		Prefix: c.Prefix,
+	})
+	if err != nil {
+		return err
+	}
+	ents := entsResp.GetEntries()
+	if len(ents) == 0 {
+		msg.Info("No entries match given filters.")
+		return nil
+	}
+	if c.DryRun {
+		msg.Infof("Dry run, %d entries would be deleted:", len(ents))
+	} else {
+		msg.Infof("%d entries to be deleted:", len(ents))
+	}
+	table := MakeTableWriter([]string{"FQDN", "Tags", "Members"})
+	table.SetAutoWrapText(false)
+	for _, ent := range ents {
+		nbMembers := len(ent.GetEntry().GetMembersIpv4()) + len(ent.GetEntry().GetMembersIpv6())
+		table.Append([]string{ent.GetEntry().GetFqdn(), strings.Join(ent.GetEntry().GetTags(), ", "), strconv.Itoa(nbMembers)})
+	}
+	table.Render()
+	if c.DryRun {
+		return nil
+	}
+	confirm, err := Confirm(c.Force)
+	if err != nil {
+		return err
+	}
+	if !confirm {
+		return nil
+	}
+
+	results := make([]*BulkResult, len(ents))
+	RunConcurrently(len(ents), c.Concurrency, func(i int) {
+		results[i] = &BulkResult{Name: ents[i].GetEntry().GetFqdn()}
+		item, err := c.delete(&gslbsvc.SetEntryRequest{
+			Entry:       ents[i].GetEntry(),
+			Healthcheck: ents[i].GetHealthcheck(),
+		})
+		if err != nil {
+			results[i].Err = err
+			return
+		}
+		results[i].TrashId = item.Id
+	})
+	return PrintBulkResults("FQDN", results)
+}
+
+func (c *DeleteEntry) delete(previousEntry *gslbsvc.SetEntryRequest) (*TrashItem, error) {
+	fqdn := previousEntry.GetEntry().GetFqdn()
+	return deleteWithTrash(TrashKindEntry, fqdn, previousEntry, func() error {
+		_, err := c.client.DeleteEntry(context.Background(), &gslbsvc.DeleteEntryRequest{
+			Fqdn: fqdn,
+		})
+		return err
+	})
+}
+
 func init() {
-	desc := "Delete entry."
+	desc := "Delete an entry or all entries matching tags or prefix, deleted entries are kept in local trash to be restored with restore command."
 	cmd, err := parser.AddCommand(
 		"delete-entry",
 		desc,
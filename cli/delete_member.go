@@ -2,13 +2,25 @@ package cli
 
 import (
 	"context"
+	"fmt"
 	msg "github.com/ArthurHlt/messages"
+	"github.com/orange-cloudfoundry/gsloc-go-sdk/gsloc/api/config/entries/v1"
 	gslbsvc "github.com/orange-cloudfoundry/gsloc-go-sdk/gsloc/services/gslb/v1"
+	"strings"
 )
 
 type DeleteMember struct {
-	FQDN   *FQDN  `positional-args:"true" positional-arg-name:"'fqdn'" required:"true"`
-	Ip     string `short:"i" long:"ip" description:"IP of the member to delete." required:"true"`
+	FQDN *FQDN  `positional-args:"true" positional-arg-name:"'fqdn'"`
+	Ip   string `short:"i" long:"ip" description:"IP of the member to delete, without fqdn member is deleted from every entry."`
+	DC   string `short:"d" long:"dc" description:"Delete all members in this datacenter."`
+
+	Tags        []string `short:"t" long:"tag" description:"Only delete members of entries with tag(s) when no fqdn is given (can be set multiple times)"`
+	Prefix      string   `short:"p" long:"prefix" description:"Only delete members of entries with prefix when no fqdn is given"`
+	Concurrency int      `long:"concurrency" description:"Number of entries updated at the same time when deleting multiple members" default:"5"`
+
+	DryRun bool `long:"dry-run" description:"Only show members which would be deleted"`
+	Force  bool `long:"force" description:"Delete members without confirmation"`
+
 	client gslbsvc.GSLBClient
 }
 
@@ -19,19 +31,135 @@ func (c *DeleteMember) SetClient(client gslbsvc.GSLBClient) {
 var deleteMember DeleteMember
 
 func (c *DeleteMember) Execute([]string) error {
-	_, err := c.client.DeleteMember(context.Background(), &gslbsvc.DeleteMemberRequest{
+	if c.Ip == "" && c.DC == "" {
+		return fmt.Errorf("one of --ip or --dc must be set")
+	}
+	if !c.FQDN.IsEmpty() && (len(c.Tags) > 0 || c.Prefix != "") {
+		return fmt.Errorf("fqdn can't be used with --tag or --prefix")
+	}
+	if c.FQDN.IsEmpty() || c.DC != "" {
+		return c.executeBulk()
+	}
+
+	resp, err := c.client.GetMember(context.Background(), &gslbsvc.GetMemberRequest{
 		Fqdn: c.FQDN.String(),
 		Ip:   c.Ip,
 	})
 	if err != nil {
 		return err
 	}
-	msg.Successf("Member %s deleted", msg.Cyan(c.FQDN))
+	previousMember := &gslbsvc.SetMemberRequest{
+		Fqdn:   c.FQDN.String(),
+		Member: resp.GetMember(),
+	}
+	if c.DryRun {
+		return DryRunDiff(previousMember, nil)
+	}
+	confirm, err := DiffAndConfirm(previousMember, nil, c.Force)
+	if err != nil {
+		return err
+	}
+	if !confirm {
+		return nil
+	}
+	item, err := c.delete(previousMember)
+	if err != nil {
+		return err
+	}
+	msg.Successf("Member %s deleted, use %s to undo.", msg.Cyan(c.Ip), msg.Yellow("restore "+item.Id))
 	return nil
 }
 
+func (c *DeleteMember) executeBulk() error {
+	ents := make([]*entries.Entry, 0)
+	if !c.FQDN.IsEmpty() {
+		entResp, err := c.client.GetEntry(context.Background(), &gslbsvc.GetEntryRequest{
+			Fqdn: c.FQDN.String(),
+		})
+		if err != nil {
+			return err
+		}
+		ents = append(ents, entResp.GetEntry())
+	} else {
+		entsResp, err := c.client.ListEntries(context.Background(), &gslbsvc.ListEntriesRequest{
+			Tags:   c.Tags,
+			Prefix: c.Prefix,
+		})
+		if err != nil {
+			return err
+		}
+		for _, ent := range entsResp.GetEntries() {
+			ents = append(ents, ent.GetEntry())
+		}
+	}
+
+	groups := MembersToDelete(ents, c.Ip, c.DC)
+	nbMembers := 0
+	for _, group := range groups {
+		nbMembers += len(group)
+	}
+	if nbMembers == 0 {
+		msg.Info("No members match given filters.")
+		return nil
+	}
+	if c.DryRun {
+		msg.Infof("Dry run, %d members would be deleted:", nbMembers)
+	} else {
+		msg.Infof("%d members to be deleted:", nbMembers)
+	}
+	table := MakeTableWriter([]string{"FQDN", "IP", "DC"})
+	for _, group := range groups {
+		for _, member := range group {
+			table.Append([]string{member.GetFqdn(), member.GetMember().GetIp(), member.GetMember().GetDc()})
+		}
+	}
+	table.Render()
+	if c.DryRun {
+		return nil
+	}
+	confirm, err := Confirm(c.Force)
+	if err != nil {
+		return err
+	}
+	if !confirm {
+		return nil
+	}
+
+	// members of an entry are deleted one after another as each deletion updates the whole entry
+	groupsResults := make([][]*BulkResult, len(groups))
+	RunConcurrently(len(groups), c.Concurrency, func(i int) {
+		for _, member := range groups[i] {
+			result := &BulkResult{Name: member.GetFqdn() + " " + member.GetMember().GetIp()}
+			groupsResults[i] = append(groupsResults[i], result)
+			item, err := c.delete(member)
+			if err != nil {
+				result.Err = err
+				continue
+			}
+			result.TrashId = item.Id
+		}
+	})
+	results := make([]*BulkResult, 0, nbMembers)
+	for _, groupResults := range groupsResults {
+		results = append(results, groupResults...)
+	}
+	return PrintBulkResults("Member", results)
+}
+
+func (c *DeleteMember) delete(previousMember *gslbsvc.SetMemberRequest) (*TrashItem, error) {
+	fqdn := previousMember.GetFqdn()
+	ip := previousMember.GetMember().GetIp()
+	return deleteWithTrash(TrashKindMember, strings.TrimSuffix(fqdn, ".")+"_"+ip, previousMember, func() error {
+		_, err := c.client.DeleteMember(context.Background(), &gslbsvc.DeleteMemberRequest{
+			Fqdn: fqdn,
+			Ip:   ip,
+		})
+		return err
+	})
+}
+
 func init() {
-	desc := "Delete member."
+	desc := "Delete a member or all members matching ip or datacenter across entries, deleted members are kept in local trash to be restored with restore command."
 	cmd, err := parser.AddCommand(
 		"delete-member",
 		desc,
@@ -0,0 +1,203 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"github.com/ArthurHlt/go-flags"
+	msg "github.com/ArthurHlt/messages"
+	"github.com/orange-cloudfoundry/gsloc-cli/app"
+	gslbsvc "github.com/orange-cloudfoundry/gsloc-go-sdk/gsloc/services/gslb/v1"
+	"sort"
+)
+
+const DriftExitCode = 2
+
+type Diff struct {
+	File       flags.Filename `short:"f" long:"file" description:"Path to a json or yml file or to a directory of files with entries definitions to compare with server"`
+	Prune      bool           `long:"prune" description:"Show entries which have managed tag but are not in definitions as deleted, --managed-tag must be set"`
+	ManagedTag string         `long:"managed-tag" description:"Tag given to apply command with --managed-tag, added to every entry when set"`
+
+	With   *TargetName `short:"w" long:"with" description:"Name of a target to compare entries with, instead of definitions"`
+	Tags   []string    `short:"t" long:"tag" description:"Filter by tag(s) when comparing with a target (can be set multiple times)."`
+	Prefix string      `short:"p" long:"prefix" description:"Filter by prefix when comparing with a target."`
+
+	client gslbsvc.GSLBClient
+}
+
+var diff Diff
+
+func (c *Diff) SetClient(client gslbsvc.GSLBClient) {
+	c.client = client
+}
+
+func (c *Diff) Execute([]string) error {
+	if (c.File == "") == (c.With == nil) {
+		return fmt.Errorf("one of --file or --with must be set")
+	}
+	var changes []*EntryChange
+	var err error
+	if c.File != "" {
+		changes, err = c.manifestsChanges()
+	} else {
+		changes, err = c.targetsChanges()
+	}
+	if err != nil {
+		return err
+	}
+	if len(changes) == 0 {
+		if IsMachineDiffFormat(opts.DiffFormat) {
+			return WriteEntriesChangesMachine(msg.Output(), changes, opts.DiffFormat)
+		}
+		msg.Info("No differences found.")
+		return nil
+	}
+	if c.File != "" {
+		err = PrintEntriesChanges(changes)
+	} else {
+		err = c.printTargetsChanges(changes)
+	}
+	if err != nil {
+		return err
+	}
+	return &ExitError{Code: DriftExitCode}
+}
+
+func (c *Diff) manifestsChanges() ([]*EntryChange, error) {
+	manifests, err := LoadEntryManifests(string(c.File))
+	if err != nil {
+		return nil, err
+	}
+	for _, manifest := range manifests {
+		AddTag(manifest.Msg.GetEntry(), c.ManagedTag)
+	}
+	entsResp, err := c.client.ListEntries(context.Background(), &gslbsvc.ListEntriesRequest{})
+	if err != nil {
+		return nil, err
+	}
+	changes, err := EntriesChanges(manifests, entsResp.GetEntries())
+	if err != nil {
+		return nil, err
+	}
+	if !c.Prune {
+		return changes, nil
+	}
+	pruneChanges, err := PruneChanges(manifests, entsResp.GetEntries(), c.ManagedTag)
+	if err != nil {
+		return nil, err
+	}
+	return append(changes, pruneChanges...), nil
+}
+
+func (c *Diff) targetsChanges() ([]*EntryChange, error) {
+	withConn, err := app.CreateConnFromFile(ExpandConfigPath(), c.With.String())
+	if err != nil {
+		return nil, err
+	}
+	defer withConn.Close() // nolint:errcheck
+	withClient := app.MakeClient(withConn)
+
+	listReq := &gslbsvc.ListEntriesRequest{
+		Tags:   c.Tags,
+		Prefix: c.Prefix,
+	}
+	entsResp, err := c.client.ListEntries(context.Background(), listReq)
+	if err != nil {
+		return nil, err
+	}
+	withEntsResp, err := withClient.ListEntries(context.Background(), listReq)
+	if err != nil {
+		return nil, fmt.Errorf("target %s: %w", c.With, err)
+	}
+
+	toSetEntry := func(ent *gslbsvc.GetEntryResponse) *gslbsvc.SetEntryRequest {
+		return &gslbsvc.SetEntryRequest{
+			Entry:       ent.GetEntry(),
+			Healthcheck: ent.GetHealthcheck(),
+		}
+	}
+	fqdns := make([]string, 0)
+	current := make(map[string]*gslbsvc.SetEntryRequest)
+	for _, ent := range entsResp.GetEntries() {
+		current[ent.GetEntry().GetFqdn()] = toSetEntry(ent)
+		fqdns = append(fqdns, ent.GetEntry().GetFqdn())
+	}
+	with := make(map[string]*gslbsvc.SetEntryRequest)
+	for _, ent := range withEntsResp.GetEntries() {
+		with[ent.GetEntry().GetFqdn()] = toSetEntry(ent)
+		if _, ok := current[ent.GetEntry().GetFqdn()]; !ok {
+			fqdns = append(fqdns, ent.GetEntry().GetFqdn())
+		}
+	}
+	sort.Strings(fqdns)
+
+	changes := make([]*EntryChange, 0)
+	for _, fqdn := range fqdns {
+		change := &EntryChange{
+			Action:   changeUpdate,
+			Fqdn:     fqdn,
+			Previous: current[fqdn],
+			Current:  with[fqdn],
+		}
+		switch {
+		case change.Previous == nil:
+			change.Action = changeCreate
+			change.Report, err = ProtoDiffReport(nil, change.Current)
+		case change.Current == nil:
+			change.Action = changeDelete
+			change.Report, err = ProtoDiffReport(change.Previous, nil)
+		default:
+			change.Report, err = ProtoDiffReport(change.Previous, change.Current)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if len(change.Report.Diffs) == 0 {
+			continue
+		}
+		changes = append(changes, change)
+	}
+	return changes, nil
+}
+
+func (c *Diff) printTargetsChanges(changes []*EntryChange) error {
+	if IsMachineDiffFormat(opts.DiffFormat) {
+		return WriteEntriesChangesMachine(msg.Output(), changes, opts.DiffFormat)
+	}
+	currentName := opts.Target
+	if currentName == "" {
+		currentName = "current target"
+	}
+	msg.Infof("Differences from %s to %s:", msg.Cyan(currentName), msg.Cyan(c.With))
+	for _, change := range changes {
+		msg.Printf("━━━━━\n")
+		switch change.Action {
+		case changeCreate:
+			msg.Infof("Entry %s only exists in %s", msg.Cyan(change.Fqdn), msg.Cyan(c.With))
+		case changeDelete:
+			msg.Infof("Entry %s only exists in %s", msg.Cyan(change.Fqdn), msg.Cyan(currentName))
+		default:
+			msg.Infof("Entry %s differs", msg.Cyan(change.Fqdn))
+		}
+		err := WriteProtoDiff(msg.Output(), change.Fqdn, change.Previous, change.Current, change.Report, opts.DiffFormat)
+		if err != nil {
+			return err
+		}
+	}
+	msg.Printf("━━━━━\n")
+	msg.Infof("%d entries differ.", len(changes))
+	return nil
+}
+
+func init() {
+	desc := "Show differences between definitions and server or between two targets without applying anything, " +
+		"exit with code 2 when differences are found."
+	cmd, err := parser.AddCommand(
+		"diff",
+		desc,
+		desc,
+		&diff)
+	if err != nil {
+		panic(err)
+	}
+	cmd.Aliases = []string{"df"}
+}
@@ -0,0 +1,291 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/homeport/dyff/pkg/dyff"
+	"google.golang.org/protobuf/proto"
+	"io"
+	"strings"
+)
+
+const (
+	DiffFormatHuman   = "human"
+	DiffFormatJson    = "json"
+	DiffFormatUnified = "unified"
+	DiffFormatGoPatch = "gopatch"
+
+	unifiedContextLines = 3
+)
+
+type DiffChange struct {
+	Path string `json:"path"`
+	Kind string `json:"kind"`
+	From any    `json:"from"`
+	To   any    `json:"to"`
+}
+
+type EntryDiffChanges struct {
+	Fqdn    string        `json:"fqdn"`
+	Action  string        `json:"action"`
+	Changes []*DiffChange `json:"changes"`
+}
+
+// IsMachineDiffFormat tells if diff is meant to be read by tools, in this case
+// diff must be written alone without any other message on stdout.
+func IsMachineDiffFormat(format string) bool {
+	return format == DiffFormatJson || format == DiffFormatUnified
+}
+
+// WriteProtoDiff write diff between two messages in given format, report must come from ProtoDiffReport(from, dest).
+func WriteProtoDiff(w io.Writer, name string, from, dest proto.Message, report dyff.Report, format string) error {
+	switch format {
+	case DiffFormatJson:
+		return writeJson(w, ReportToDiffChanges(report))
+	case DiffFormatUnified:
+		return WriteUnifiedProtoDiff(w, name, from, dest)
+	default:
+		reportWriter := &dyff.HumanReport{
+			Report:               report,
+			NoTableStyle:         true,
+			OmitHeader:           true,
+			UseGoPatchPaths:      format == DiffFormatGoPatch,
+			MinorChangeThreshold: 0.1,
+		}
+		return reportWriter.WriteReport(w)
+	}
+}
+
+// WriteEntriesChangesMachine write changes on multiple entries in a machine diff format.
+func WriteEntriesChangesMachine(w io.Writer, changes []*EntryChange, format string) error {
+	if format == DiffFormatJson {
+		entriesChanges := make([]*EntryDiffChanges, len(changes))
+		for i, change := range changes {
+			entriesChanges[i] = &EntryDiffChanges{
+				Fqdn:    change.Fqdn,
+				Action:  change.Action,
+				Changes: ReportToDiffChanges(change.Report),
+			}
+		}
+		return writeJson(w, entriesChanges)
+	}
+	for _, change := range changes {
+		err := WriteUnifiedProtoDiff(w, change.Fqdn, change.Previous, change.Current)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func ReportToDiffChanges(report dyff.Report) []*DiffChange {
+	changes := make([]*DiffChange, 0)
+	for _, diff := range report.Diffs {
+		path := "/"
+		if diff.Path != nil {
+			path = diff.Path.ToGoPatchStyle()
+		}
+		for _, detail := range diff.Details {
+			change := &DiffChange{
+				Path: path,
+				Kind: diffKindName(detail.Kind),
+			}
+			if detail.From != nil {
+				// nolint:errcheck
+				detail.From.Decode(&change.From)
+			}
+			if detail.To != nil {
+				// nolint:errcheck
+				detail.To.Decode(&change.To)
+			}
+			changes = append(changes, change)
+		}
+	}
+	return changes
+}
+
+func diffKindName(kind rune) string {
+	switch kind {
+	case dyff.ADDITION:
+		return "addition"
+	case dyff.REMOVAL:
+		return "removal"
+	case dyff.ORDERCHANGE:
+		return "order-change"
+	default:
+		return "modification"
+	}
+}
+
+func writeJson(w io.Writer, v any) error {
+	b, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(w, string(b))
+	return err
+}
+
+func isEmptyProto(pMsg proto.Message) bool {
+	return pMsg == nil || !pMsg.ProtoReflect().IsValid()
+}
+
+// WriteUnifiedProtoDiff write a unified diff of messages in yml form, absent messages are seen as empty files.
+func WriteUnifiedProtoDiff(w io.Writer, name string, from, dest proto.Message) error {
+	var fromYaml, destYaml []byte
+	var err error
+	if !isEmptyProto(from) {
+		fromYaml, err = ProtoToYaml(from)
+		if err != nil {
+			return err
+		}
+	}
+	if !isEmptyProto(dest) {
+		destYaml, err = ProtoToYaml(dest)
+		if err != nil {
+			return err
+		}
+	}
+	_, err = io.WriteString(w, UnifiedDiff("a/"+name, "b/"+name, string(fromYaml), string(destYaml)))
+	return err
+}
+
+type diffLine struct {
+	op   byte
+	text string
+}
+
+// UnifiedDiff gives a unified diff between two texts, empty string is given when texts are equals.
+func UnifiedDiff(fromName, toName, from, to string) string {
+	lines := diffLines(splitLines(from), splitLines(to))
+	changed := false
+	for _, l := range lines {
+		if l.op != ' ' {
+			changed = true
+			break
+		}
+	}
+	if !changed {
+		return ""
+	}
+
+	buf := &strings.Builder{}
+	fmt.Fprintf(buf, "--- %s\n+++ %s\n", fromName, toName)
+	i := 0
+	for i < len(lines) {
+		if lines[i].op == ' ' {
+			i++
+			continue
+		}
+		start := i - unifiedContextLines
+		if start < 0 {
+			start = 0
+		}
+		// extend hunk while changes are separated by less than two contexts
+		end := i
+		for end < len(lines) {
+			if lines[end].op != ' ' {
+				end++
+				continue
+			}
+			next := end
+			for next < len(lines) && lines[next].op == ' ' {
+				next++
+			}
+			if next == len(lines) || next-end > 2*unifiedContextLines {
+				end += unifiedContextLines
+				if end > len(lines) {
+					end = len(lines)
+				}
+				break
+			}
+			end = next
+		}
+		writeHunk(buf, lines, start, end)
+		i = end
+	}
+	return buf.String()
+}
+
+func writeHunk(buf *strings.Builder, lines []diffLine, start, end int) {
+	fromStart, toStart := 1, 1
+	for _, l := range lines[:start] {
+		if l.op != '+' {
+			fromStart++
+		}
+		if l.op != '-' {
+			toStart++
+		}
+	}
+	fromCount, toCount := 0, 0
+	for _, l := range lines[start:end] {
+		if l.op != '+' {
+			fromCount++
+		}
+		if l.op != '-' {
+			toCount++
+		}
+	}
+	if fromCount == 0 {
+		fromStart--
+	}
+	if toCount == 0 {
+		toStart--
+	}
+	fmt.Fprintf(buf, "@@ -%d,%d +%d,%d @@\n", fromStart, fromCount, toStart, toCount)
+	for _, l := range lines[start:end] {
+		buf.WriteByte(l.op)
+		buf.WriteString(l.text)
+		buf.WriteByte('\n')
+	}
+}
+
+func splitLines(s string) []string {
+	if s == "" {
+		return []string{}
+	}
+	return strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+}
+
+// diffLines compute line operations from longest common subsequence, texts are small yml files
+// so quadratic complexity is acceptable.
+func diffLines(from, to []string) []diffLine {
+	lcs := make([][]int, len(from)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(to)+1)
+	}
+	for i := len(from) - 1; i >= 0; i-- {
+		for j := len(to) - 1; j >= 0; j-- {
+			if from[i] == to[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+	lines := make([]diffLine, 0, len(from)+len(to))
+	i, j := 0, 0
+	for i < len(from) && j < len(to) {
+		switch {
+		case from[i] == to[j]:
+			lines = append(lines, diffLine{op: ' ', text: from[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			lines = append(lines, diffLine{op: '-', text: from[i]})
+			i++
+		default:
+			lines = append(lines, diffLine{op: '+', text: to[j]})
+			j++
+		}
+	}
+	for ; i < len(from); i++ {
+		lines = append(lines, diffLine{op: '-', text: from[i]})
+	}
+	for ; j < len(to); j++ {
+		lines = append(lines, diffLine{op: '+', text: to[j]})
+	}
+	return lines
+}
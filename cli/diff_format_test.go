@@ -0,0 +1,77 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/orange-cloudfoundry/gsloc-go-sdk/gsloc/api/config/entries/v1"
+	gslbsvc "github.com/orange-cloudfoundry/gsloc-go-sdk/gsloc/services/gslb/v1"
+)
+
+func TestUnifiedDiff(t *testing.T) {
+	from := "a\nb\nc\nd\ne\nf\ng\nh\ni\nj\nk\nl\n"
+	to := "a\nB\nc\nd\ne\nf\ng\nh\ni\nj\nk\nl\nm\n"
+	expected := `--- a/file
++++ b/file
+@@ -1,5 +1,5 @@
+ a
+-b
++B
+ c
+ d
+ e
+@@ -10,3 +10,4 @@
+ j
+ k
+ l
++m
+`
+	result := UnifiedDiff("a/file", "b/file", from, to)
+	if result != expected {
+		t.Errorf("Expected:\n%s\nbut got:\n%s", expected, result)
+	}
+
+	if UnifiedDiff("a/file", "b/file", from, from) != "" {
+		t.Errorf("Expected empty diff for same content")
+	}
+
+	expected = `--- a/file
++++ b/file
+@@ -0,0 +1,2 @@
++a
++b
+`
+	result = UnifiedDiff("a/file", "b/file", "", "a\nb\n")
+	if result != expected {
+		t.Errorf("Expected:\n%s\nbut got:\n%s", expected, result)
+	}
+}
+
+func TestReportToDiffChanges(t *testing.T) {
+	from := &gslbsvc.SetMemberRequest{Fqdn: "foo.com.", Member: &entries.Member{Ip: "10.0.0.1", Ratio: 1}}
+	dest := &gslbsvc.SetMemberRequest{Fqdn: "foo.com.", Member: &entries.Member{Ip: "10.0.0.1", Ratio: 2}}
+	report, err := ProtoDiffReport(from, dest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	buf := &bytes.Buffer{}
+	err = WriteProtoDiff(buf, "foo.com.", from, dest, report, DiffFormatJson)
+	if err != nil {
+		t.Fatal(err)
+	}
+	changes := make([]map[string]any, 0)
+	err = json.Unmarshal(buf.Bytes(), &changes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != 1 {
+		t.Fatalf("Expected 1 change but got %d: %s", len(changes), buf.String())
+	}
+	if changes[0]["path"] != "/member/ratio" || changes[0]["kind"] != "modification" {
+		t.Errorf("Unexpected change %v", changes[0])
+	}
+	if changes[0]["from"] != float64(1) || changes[0]["to"] != float64(2) {
+		t.Errorf("Unexpected values in change %v", changes[0])
+	}
+}
@@ -0,0 +1,207 @@
+package cli
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"github.com/ArthurHlt/go-flags"
+	msg "github.com/ArthurHlt/messages"
+	gslbsvc "github.com/orange-cloudfoundry/gsloc-go-sdk/gsloc/services/gslb/v1"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"io"
+	"os"
+	"path/filepath"
+	kyaml "sigs.k8s.io/yaml"
+	"strings"
+	"time"
+)
+
+type Export struct {
+	Tags   []string `short:"t" long:"tag" description:"Filter by tag(s) (can be set multiple times)."`
+	Prefix string   `short:"p" long:"prefix" description:"Filter by prefix."`
+
+	File    flags.Filename `short:"f" long:"file" description:"Write all entries in a single multi-document yml file instead of stdout"`
+	Dir     flags.Filename `short:"d" long:"dir" description:"Write one yml file per entry in this directory"`
+	Archive flags.Filename `short:"a" long:"archive" description:"Write one yml file per entry in a tar.gz archive"`
+
+	client gslbsvc.GSLBClient
+}
+
+var export Export
+
+func (c *Export) SetClient(client gslbsvc.GSLBClient) {
+	c.client = client
+}
+
+func (c *Export) Execute([]string) error {
+	nbOutputs := 0
+	for _, output := range []flags.Filename{c.File, c.Dir, c.Archive} {
+		if output != "" {
+			nbOutputs++
+		}
+	}
+	if nbOutputs > 1 {
+		return fmt.Errorf("only one of --file, --dir or --archive can be set")
+	}
+
+	entsResp, err := c.client.ListEntries(context.Background(), &gslbsvc.ListEntriesRequest{
+		Tags:   c.Tags,
+		Prefix: c.Prefix,
+	})
+	if err != nil {
+		return err
+	}
+
+	msg.UseStderr()
+	defer msg.UseStdout()
+	if len(entsResp.GetEntries()) == 0 {
+		msg.Info("No entries found.")
+		return nil
+	}
+
+	switch {
+	case c.Dir != "":
+		err = c.exportToDir(entsResp.GetEntries())
+	case c.Archive != "":
+		err = c.exportToArchive(entsResp.GetEntries())
+	case c.File != "":
+		err = c.exportToFile(entsResp.GetEntries())
+	default:
+		err = c.exportToStream(os.Stdout, entsResp.GetEntries())
+	}
+	if err != nil {
+		return err
+	}
+	if nbOutputs > 0 {
+		msg.Successf("%d entries exported.", len(entsResp.GetEntries()))
+	}
+	return nil
+}
+
+func (c *Export) exportToStream(w io.Writer, ents []*gslbsvc.GetEntryResponse) error {
+	for i, ent := range ents {
+		content, err := EntryToManifest(ent)
+		if err != nil {
+			return err
+		}
+		if i > 0 {
+			_, err = w.Write([]byte("---\n"))
+			if err != nil {
+				return err
+			}
+		}
+		_, err = w.Write(content)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *Export) exportToFile(ents []*gslbsvc.GetEntryResponse) error {
+	buf := &bytes.Buffer{}
+	err := c.exportToStream(buf, ents)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(string(c.File), buf.Bytes(), 0644)
+}
+
+func (c *Export) exportToDir(ents []*gslbsvc.GetEntryResponse) error {
+	err := os.MkdirAll(string(c.Dir), 0755)
+	if err != nil {
+		return err
+	}
+	for _, ent := range ents {
+		content, err := EntryToManifest(ent)
+		if err != nil {
+			return err
+		}
+		err = os.WriteFile(filepath.Join(string(c.Dir), EntryManifestFilename(ent.GetEntry().GetFqdn())), content, 0644)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *Export) exportToArchive(ents []*gslbsvc.GetEntryResponse) error {
+	f, err := os.Create(string(c.Archive))
+	if err != nil {
+		return err
+	}
+	defer f.Close() // nolint:errcheck
+
+	gzWriter := gzip.NewWriter(f)
+	tarWriter := tar.NewWriter(gzWriter)
+	now := time.Now()
+	for _, ent := range ents {
+		content, err := EntryToManifest(ent)
+		if err != nil {
+			return err
+		}
+		err = tarWriter.WriteHeader(&tar.Header{
+			Name:    EntryManifestFilename(ent.GetEntry().GetFqdn()),
+			Mode:    0644,
+			Size:    int64(len(content)),
+			ModTime: now,
+		})
+		if err != nil {
+			return err
+		}
+		_, err = tarWriter.Write(content)
+		if err != nil {
+			return err
+		}
+	}
+	err = tarWriter.Close()
+	if err != nil {
+		return err
+	}
+	err = gzWriter.Close()
+	if err != nil {
+		return err
+	}
+	return f.Close()
+}
+
+// EntryToManifest convert an entry from server to a set entry request in yml
+// which can be read back by set-entry or apply commands.
+func EntryToManifest(ent *gslbsvc.GetEntryResponse) ([]byte, error) {
+	return ProtoToManifest(&gslbsvc.SetEntryRequest{
+		Entry:       ent.GetEntry(),
+		Healthcheck: ent.GetHealthcheck(),
+	})
+}
+
+// ProtoToManifest convert a proto message to yml without losing precision on numbers
+// unlike ProtoToYaml, only populated fields are written.
+func ProtoToManifest(pMsg proto.Message) ([]byte, error) {
+	data, err := protojson.MarshalOptions{
+		UseProtoNames: true,
+	}.Marshal(pMsg)
+	if err != nil {
+		return nil, err
+	}
+	return kyaml.JSONToYAML(data)
+}
+
+func EntryManifestFilename(fqdn string) string {
+	return strings.TrimSuffix(fqdn, ".") + ".yml"
+}
+
+func init() {
+	desc := "Export entries as definitions which can be used by set-entry or apply commands."
+	cmd, err := parser.AddCommand(
+		"export",
+		desc,
+		desc,
+		&export)
+	if err != nil {
+		panic(err)
+	}
+	cmd.Aliases = []string{"ex"}
+}
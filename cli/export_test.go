@@ -0,0 +1,100 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/orange-cloudfoundry/gsloc-go-sdk/gsloc/api/config/entries/v1"
+	hcconf "github.com/orange-cloudfoundry/gsloc-go-sdk/gsloc/api/config/healthchecks/v1"
+	gslbsvc "github.com/orange-cloudfoundry/gsloc-go-sdk/gsloc/services/gslb/v1"
+	gsloctype "github.com/orange-cloudfoundry/gsloc-go-sdk/gsloc/type/v1"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+func TestEntryToManifestRoundTrip(t *testing.T) {
+	pluginOpts, err := structpb.NewStruct(map[string]any{
+		"url":     "http://localhost",
+		"retries": 3,
+		"nested":  map[string]any{"enabled": true},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ents := []*gslbsvc.GetEntryResponse{
+		{
+			Entry: &entries.Entry{
+				Fqdn:              "foo.example.com.",
+				LbAlgoPreferred:   entries.LBAlgo_TOPOLOGY,
+				MaxAnswerReturned: 4294967295,
+				Ttl:               30,
+				Tags:              []string{"app"},
+				MembersIpv4: []*entries.Member{
+					{Ip: "10.0.0.1", Dc: "dc1", Ratio: 2, Disabled: true},
+				},
+			},
+			Healthcheck: &hcconf.HealthCheck{
+				Timeout:  durationpb.New(1500000000),
+				Interval: durationpb.New(30000000000),
+				Port:     443,
+				HealthChecker: &hcconf.HealthCheck_HttpHealthCheck{
+					HttpHealthCheck: &hcconf.HttpHealthCheck{
+						Path: "/health",
+						Send: &hcconf.HealthCheckPayload{
+							Payload: &hcconf.HealthCheckPayload_Binary{Binary: []byte{0x00, 0xff, 0x10}},
+						},
+						ExpectedStatuses: &gsloctype.Int64Range{Start: 200, End: 299},
+					},
+				},
+			},
+		},
+		{
+			Entry: &entries.Entry{Fqdn: "bar.example.com."},
+			Healthcheck: &hcconf.HealthCheck{
+				HealthChecker: &hcconf.HealthCheck_PluginHealthCheck{
+					PluginHealthCheck: &hcconf.PluginHealthCheck{Name: "myplugin", Options: pluginOpts},
+				},
+			},
+		},
+		{
+			Entry: &entries.Entry{Fqdn: "baz.example.com.", Ttl: 60},
+			Healthcheck: &hcconf.HealthCheck{
+				HealthChecker: &hcconf.HealthCheck_NoHealthCheck{NoHealthCheck: &hcconf.NoHealthCheck{}},
+			},
+		},
+		{
+			Entry: &entries.Entry{Fqdn: "qux.example.com."},
+			Healthcheck: &hcconf.HealthCheck{
+				HealthChecker: &hcconf.HealthCheck_PluginHealthCheck{
+					PluginHealthCheck: &hcconf.PluginHealthCheck{Name: "myplugin", Options: &structpb.Struct{}},
+				},
+			},
+		},
+	}
+
+	dir := t.TempDir()
+	for _, ent := range ents {
+		content, err := EntryToManifest(ent)
+		if err != nil {
+			t.Fatal(err)
+		}
+		file := filepath.Join(dir, EntryManifestFilename(ent.GetEntry().GetFqdn()))
+		err = os.WriteFile(file, content, 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+		loaded, ok, err := FileToProto[*gslbsvc.SetEntryRequest](file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !ok {
+			t.Fatalf("Expected manifest of %s to be loaded:\n%s", ent.GetEntry().GetFqdn(), content)
+		}
+		expected := &gslbsvc.SetEntryRequest{Entry: ent.GetEntry(), Healthcheck: ent.GetHealthcheck()}
+		if !proto.Equal(expected, loaded) {
+			t.Errorf("Expected %v but got %v", expected, loaded)
+		}
+	}
+}
@@ -0,0 +1,269 @@
+package cli
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	msg "github.com/ArthurHlt/messages"
+	"github.com/orange-cloudfoundry/gsloc-go-sdk/gsloc/api/config/entries/v1"
+	gslbsvc "github.com/orange-cloudfoundry/gsloc-go-sdk/gsloc/services/gslb/v1"
+	"io"
+	"net/http"
+	"os"
+	"os/signal"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const metricsContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+type Exporter struct {
+	Listen      string        `short:"l" long:"listen" description:"Address to listen on for scrapes" default:":9450"`
+	MetricsPath string        `long:"metrics-path" description:"Path where metrics are exposed" default:"/metrics"`
+	Interval    time.Duration `short:"n" long:"interval" description:"Interval between two refreshes of status from target." default:"30s"`
+
+	Tags   []string `short:"t" long:"tag" description:"Filter by tag(s) (can be set multiple times)."`
+	Prefix string   `short:"p" long:"prefix" description:"Filter by prefix."`
+
+	client gslbsvc.GSLBClient
+
+	// mutex protects values below which are set on each refresh and read on each scrape
+	mutex         sync.RWMutex
+	entries       []*gslbsvc.GetEntryResponse
+	entriesStatus []*gslbsvc.GetEntryStatusResponse
+	errorsByCall  map[string]int
+	lastSuccess   time.Time
+	up            bool
+}
+
+var exporter Exporter
+
+func (c *Exporter) SetClient(client gslbsvc.GSLBClient) {
+	c.client = client
+}
+
+func (c *Exporter) Execute([]string) error {
+	if c.Interval <= 0 {
+		return fmt.Errorf("interval must be positive")
+	}
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer cancel()
+
+	go func() {
+		c.Refresh(ctx)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(c.Interval):
+				c.Refresh(ctx)
+			}
+		}
+	}()
+
+	mux := http.NewServeMux()
+	mux.Handle(c.MetricsPath, c)
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/" {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprintf(w, "<html><body><h1>gsloc exporter</h1><a href=\"%s\">Metrics</a></body></html>\n", c.MetricsPath)
+	})
+	server := &http.Server{
+		Addr:              c.Listen,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	go func() {
+		<-ctx.Done()
+		server.Shutdown(context.Background()) // nolint:errcheck
+	}()
+
+	msg.Infof("Exposing metrics on %s%s, status refreshed every %s (ctrl+c to quit)", c.Listen, c.MetricsPath, c.Interval)
+	err := server.ListenAndServe()
+	if errors.Is(err, http.ErrServerClosed) {
+		return nil
+	}
+	return err
+}
+
+// Refresh fetch entries and their status from target, previous values are kept for a call in error.
+// Calls which do not answer before next refresh are in error.
+func (c *Exporter) Refresh(ctx context.Context) {
+	ctx, cancel := context.WithTimeout(ctx, c.Interval)
+	defer cancel()
+	entsResp, entsErr := c.client.ListEntries(ctx, &gslbsvc.ListEntriesRequest{
+		Tags:   c.Tags,
+		Prefix: c.Prefix,
+	})
+	statusResp, statusErr := c.client.ListEntriesStatus(ctx, &gslbsvc.ListEntriesStatusRequest{
+		Tags:   c.Tags,
+		Prefix: c.Prefix,
+	})
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.errorsByCall == nil {
+		c.errorsByCall = map[string]int{"ListEntries": 0, "ListEntriesStatus": 0}
+	}
+	for call, err := range map[string]error{"ListEntries": entsErr, "ListEntriesStatus": statusErr} {
+		if err == nil {
+			continue
+		}
+		c.errorsByCall[call]++
+		msg.UseStderr()
+		msg.Error(fmt.Sprintf("Failed to refresh with %s: %s", call, err.Error()))
+		msg.UseStdout()
+	}
+	if entsErr == nil {
+		c.entries = entsResp.GetEntries()
+	}
+	if statusErr == nil {
+		c.entriesStatus = statusResp.GetEntriesStatus()
+	}
+	c.up = entsErr == nil && statusErr == nil
+	if c.up {
+		c.lastSuccess = time.Now()
+	}
+}
+
+func (c *Exporter) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", metricsContentType)
+	c.WriteMetrics(w) // nolint:errcheck
+}
+
+// WriteMetrics write metrics from last refresh in prometheus text format.
+func (c *Exporter) WriteMetrics(w io.Writer) error {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	up := 0.0
+	if c.up {
+		up = 1
+	}
+	lastSuccess := 0.0
+	if !c.lastSuccess.IsZero() {
+		lastSuccess = float64(c.lastSuccess.UnixMilli()) / 1000
+	}
+	errorsTotal := make([]metricSample, 0, len(c.errorsByCall))
+	for call, nb := range c.errorsByCall {
+		errorsTotal = append(errorsTotal, metricSample{labels: []string{"call", call}, value: float64(nb)})
+	}
+
+	disabled := make([]metricSample, 0)
+	for _, ent := range c.entries {
+		members := make([]*entries.Member, 0, len(ent.GetEntry().GetMembersIpv4())+len(ent.GetEntry().GetMembersIpv6()))
+		members = append(members, ent.GetEntry().GetMembersIpv4()...)
+		for _, member := range append(members, ent.GetEntry().GetMembersIpv6()...) {
+			value := 0.0
+			if member.GetDisabled() {
+				value = 1
+			}
+			disabled = append(disabled, metricSample{
+				labels: []string{"fqdn", ent.GetEntry().GetFqdn(), "ip", member.GetIp(), "dc", member.GetDc()},
+				value:  value,
+			})
+		}
+	}
+
+	status := make([]metricSample, 0)
+	healthy := make([]metricSample, 0)
+	total := make([]metricSample, 0)
+	for _, ent := range c.entriesStatus {
+		nbByDc := make(map[string]int)
+		healthyByDc := make(map[string]int)
+		for _, member := range entryMembersStatus(ent) {
+			nbByDc[member.GetDc()]++
+			if member.GetStatus() == gslbsvc.MemberStatus_ONLINE {
+				healthyByDc[member.GetDc()]++
+			}
+			for value, name := range gslbsvc.MemberStatus_Status_name {
+				isStatus := 0.0
+				if member.GetStatus() == gslbsvc.MemberStatus_Status(value) {
+					isStatus = 1
+				}
+				status = append(status, metricSample{
+					labels: []string{"fqdn", ent.GetFqdn(), "ip", member.GetIp(), "dc", member.GetDc(), "status", name},
+					value:  isStatus,
+				})
+			}
+		}
+		for dc, nb := range nbByDc {
+			labels := []string{"fqdn", ent.GetFqdn(), "dc", dc}
+			healthy = append(healthy, metricSample{labels: labels, value: float64(healthyByDc[dc])})
+			total = append(total, metricSample{labels: labels, value: float64(nb)})
+		}
+	}
+
+	bw := bufio.NewWriter(w)
+	families := []struct {
+		name, help, kind string
+		samples          []metricSample
+	}{
+		{"gsloc_up", "Whether last refresh of status from gsloc succeeded.", "gauge", []metricSample{{value: up}}},
+		{"gsloc_last_refresh_success_timestamp_seconds", "Time of last successful refresh of status.", "gauge", []metricSample{{value: lastSuccess}}},
+		{"gsloc_scrape_errors_total", "Number of failed calls to gsloc by call.", "counter", errorsTotal},
+		{"gsloc_member_status", "Status of member, 1 for current status and 0 for others.", "gauge", status},
+		{"gsloc_member_disabled", "Whether member is disabled by user.", "gauge", disabled},
+		{"gsloc_entry_healthy_members", "Number of ONLINE members of entry in a datacenter.", "gauge", healthy},
+		{"gsloc_entry_members", "Number of members of entry in a datacenter.", "gauge", total},
+	}
+	for _, family := range families {
+		writeMetricFamily(bw, family.name, family.help, family.kind, family.samples)
+	}
+	return bw.Flush()
+}
+
+// metricSample is a value with labels given as name and value pairs.
+type metricSample struct {
+	labels []string
+	value  float64
+}
+
+func (s metricSample) labelsText() string {
+	if len(s.labels) == 0 {
+		return ""
+	}
+	pairs := make([]string, 0, len(s.labels)/2)
+	for i := 0; i+1 < len(s.labels); i += 2 {
+		pairs = append(pairs, s.labels[i]+"=\""+escapeLabelValue(s.labels[i+1])+"\"")
+	}
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+// writeMetricFamily write a metric in prometheus text format, samples are sorted by labels to keep output stable.
+func writeMetricFamily(w io.Writer, name, help, kind string, samples []metricSample) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, kind)
+	lines := make([]string, len(samples))
+	for i, sample := range samples {
+		lines[i] = name + sample.labelsText() + " " + strconv.FormatFloat(sample.value, 'g', -1, 64)
+	}
+	sort.Strings(lines)
+	for _, line := range lines {
+		fmt.Fprintln(w, line)
+	}
+}
+
+var labelValueReplacer = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func escapeLabelValue(value string) string {
+	return labelValueReplacer.Replace(value)
+}
+
+func init() {
+	desc := "Expose status of entries and members as prometheus metrics."
+	cmd, err := parser.AddCommand(
+		"exporter",
+		desc,
+		desc,
+		&exporter)
+	if err != nil {
+		panic(err)
+	}
+	cmd.Aliases = []string{"exp"}
+}
@@ -0,0 +1,118 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/orange-cloudfoundry/gsloc-go-sdk/gsloc/api/config/entries/v1"
+	gslbsvc "github.com/orange-cloudfoundry/gsloc-go-sdk/gsloc/services/gslb/v1"
+	"google.golang.org/grpc"
+)
+
+// failingStatusClient fails on status calls after a first success, or hangs until call is canceled.
+type failingStatusClient struct {
+	*fakeClient
+	fail bool
+	hang bool
+}
+
+func (f *failingStatusClient) ListEntriesStatus(ctx context.Context, req *gslbsvc.ListEntriesStatusRequest, opts ...grpc.CallOption) (*gslbsvc.ListEntriesStatusResponse, error) {
+	if f.fail {
+		return nil, fmt.Errorf("unavailable")
+	}
+	if f.hang {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	return f.fakeClient.ListEntriesStatus(ctx, req, opts...)
+}
+
+func TestExporterMetrics(t *testing.T) {
+	client := &failingStatusClient{fakeClient: &fakeClient{
+		entries: map[string]*gslbsvc.GetEntryResponse{
+			"a.example.com.": {Entry: &entries.Entry{
+				Fqdn: "a.example.com.",
+				MembersIpv4: []*entries.Member{
+					{Ip: "10.0.0.1", Dc: "dc1"},
+					{Ip: "10.0.0.2", Dc: "dc1", Disabled: true},
+				},
+			}},
+		},
+		entriesStatus: [][]*gslbsvc.GetEntryStatusResponse{{{
+			Fqdn: "a.example.com.",
+			MembersIpv4: []*gslbsvc.MemberStatus{
+				{Ip: "10.0.0.1", Dc: "dc1", Status: gslbsvc.MemberStatus_ONLINE},
+				{Ip: "10.0.0.2", Dc: "dc1", Status: gslbsvc.MemberStatus_CHECK_FAILED, FailureReason: "disabled entry"},
+			},
+		}}},
+	}}
+	exp := &Exporter{Interval: time.Minute}
+	exp.SetClient(client)
+	exp.Refresh(context.Background())
+	client.fail = true
+	exp.Refresh(context.Background())
+
+	rec := httptest.NewRecorder()
+	exp.ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	if rec.Header().Get("Content-Type") != metricsContentType {
+		t.Errorf("Expected prometheus content type, got %s", rec.Header().Get("Content-Type"))
+	}
+	body := rec.Body.String()
+	for _, expected := range []string{
+		"# TYPE gsloc_scrape_errors_total counter\n",
+		"gsloc_up 0\n",
+		`gsloc_scrape_errors_total{call="ListEntries"} 0` + "\n",
+		`gsloc_scrape_errors_total{call="ListEntriesStatus"} 1` + "\n",
+		`gsloc_member_status{fqdn="a.example.com.",ip="10.0.0.1",dc="dc1",status="ONLINE"} 1` + "\n",
+		`gsloc_member_status{fqdn="a.example.com.",ip="10.0.0.1",dc="dc1",status="CHECK_FAILED"} 0` + "\n",
+		`gsloc_member_status{fqdn="a.example.com.",ip="10.0.0.2",dc="dc1",status="CHECK_FAILED"} 1` + "\n",
+		`gsloc_member_disabled{fqdn="a.example.com.",ip="10.0.0.2",dc="dc1"} 1` + "\n",
+		`gsloc_member_disabled{fqdn="a.example.com.",ip="10.0.0.1",dc="dc1"} 0` + "\n",
+		`gsloc_entry_healthy_members{fqdn="a.example.com.",dc="dc1"} 1` + "\n",
+		`gsloc_entry_members{fqdn="a.example.com.",dc="dc1"} 2` + "\n",
+	} {
+		if !strings.Contains(body, expected) {
+			t.Errorf("Expected metrics to contain %q, got:\n%s", expected, body)
+		}
+	}
+}
+
+func TestExporterRefreshTimeout(t *testing.T) {
+	client := &failingStatusClient{fakeClient: &fakeClient{}, hang: true}
+	exp := &Exporter{Interval: 50 * time.Millisecond}
+	exp.SetClient(client)
+
+	done := make(chan struct{})
+	go func() {
+		exp.Refresh(context.Background())
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected refresh to time out on a hung call")
+	}
+
+	rec := httptest.NewRecorder()
+	exp.ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	body := rec.Body.String()
+	for _, expected := range []string{
+		"gsloc_up 0\n",
+		`gsloc_scrape_errors_total{call="ListEntriesStatus"} 1` + "\n",
+		`gsloc_scrape_errors_total{call="ListEntries"} 0` + "\n",
+	} {
+		if !strings.Contains(body, expected) {
+			t.Errorf("Expected metrics to contain %q, got:\n%s", expected, body)
+		}
+	}
+}
+
+func TestEscapeLabelValue(t *testing.T) {
+	if escaped := escapeLabelValue("a\"b\\c\nd"); escaped != `a\"b\\c\nd` {
+		t.Errorf("Unexpected escaped value %s", escaped)
+	}
+}
@@ -0,0 +1,161 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/orange-cloudfoundry/gsloc-go-sdk/gsloc/api/config/entries/v1"
+	hcconf "github.com/orange-cloudfoundry/gsloc-go-sdk/gsloc/api/config/healthchecks/v1"
+	gslbsvc "github.com/orange-cloudfoundry/gsloc-go-sdk/gsloc/services/gslb/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+// fakeClient is a GSLBClient where only status, entry, healthcheck and member calls are implemented, other calls panic.
+type fakeClient struct {
+	gslbsvc.GSLBClient
+
+	entries map[string]*gslbsvc.GetEntryResponse
+
+	entriesStatus [][]*gslbsvc.GetEntryStatusResponse
+	nbCalls       int
+
+	setMembersStatusReqs []*gslbsvc.SetMembersStatusRequest
+	setMembersStatusResp *gslbsvc.SetMembersStatusResponse
+}
+
+// nextStatus gives status of each call in order, last one is given back once all have been consumed.
+func (f *fakeClient) nextStatus() []*gslbsvc.GetEntryStatusResponse {
+	i := f.nbCalls
+	if i >= len(f.entriesStatus) {
+		i = len(f.entriesStatus) - 1
+	}
+	f.nbCalls++
+	return f.entriesStatus[i]
+}
+
+func (f *fakeClient) GetEntryStatus(context.Context, *gslbsvc.GetEntryStatusRequest, ...grpc.CallOption) (*gslbsvc.GetEntryStatusResponse, error) {
+	return f.nextStatus()[0], nil
+}
+
+func (f *fakeClient) ListEntriesStatus(context.Context, *gslbsvc.ListEntriesStatusRequest, ...grpc.CallOption) (*gslbsvc.ListEntriesStatusResponse, error) {
+	return &gslbsvc.ListEntriesStatusResponse{EntriesStatus: f.nextStatus()}, nil
+}
+
+func (f *fakeClient) SetMembersStatus(_ context.Context, req *gslbsvc.SetMembersStatusRequest, _ ...grpc.CallOption) (*gslbsvc.SetMembersStatusResponse, error) {
+	f.setMembersStatusReqs = append(f.setMembersStatusReqs, req)
+	return f.setMembersStatusResp, nil
+}
+
+func (f *fakeClient) GetEntry(_ context.Context, req *gslbsvc.GetEntryRequest, _ ...grpc.CallOption) (*gslbsvc.GetEntryResponse, error) {
+	ent, ok := f.entries[req.GetFqdn()]
+	if !ok {
+		return nil, fmt.Errorf("entry %s not found", req.GetFqdn())
+	}
+	return proto.Clone(ent).(*gslbsvc.GetEntryResponse), nil
+}
+
+func (f *fakeClient) SetEntry(_ context.Context, req *gslbsvc.SetEntryRequest, _ ...grpc.CallOption) (*emptypb.Empty, error) {
+	if f.entries == nil {
+		f.entries = make(map[string]*gslbsvc.GetEntryResponse)
+	}
+	f.entries[req.GetEntry().GetFqdn()] = &gslbsvc.GetEntryResponse{
+		Entry:       proto.Clone(req.GetEntry()).(*entries.Entry),
+		Healthcheck: req.GetHealthcheck(),
+	}
+	return &emptypb.Empty{}, nil
+}
+
+func (f *fakeClient) GetHealthCheck(_ context.Context, req *gslbsvc.GetHealthCheckRequest, _ ...grpc.CallOption) (*gslbsvc.GetHealthCheckResponse, error) {
+	ent, ok := f.entries[req.GetFqdn()]
+	if !ok {
+		return nil, fmt.Errorf("entry %s not found", req.GetFqdn())
+	}
+	return &gslbsvc.GetHealthCheckResponse{Healthcheck: proto.Clone(ent.GetHealthcheck()).(*hcconf.HealthCheck)}, nil
+}
+
+func (f *fakeClient) SetHealthCheck(_ context.Context, req *gslbsvc.SetHealthCheckRequest, _ ...grpc.CallOption) (*emptypb.Empty, error) {
+	ent, ok := f.entries[req.GetFqdn()]
+	if !ok {
+		return nil, fmt.Errorf("entry %s not found", req.GetFqdn())
+	}
+	ent.Healthcheck = proto.Clone(req.GetHealthcheck()).(*hcconf.HealthCheck)
+	return &emptypb.Empty{}, nil
+}
+
+func (f *fakeClient) DeleteEntry(_ context.Context, req *gslbsvc.DeleteEntryRequest, _ ...grpc.CallOption) (*emptypb.Empty, error) {
+	if _, ok := f.entries[req.GetFqdn()]; !ok {
+		return nil, fmt.Errorf("entry %s not found", req.GetFqdn())
+	}
+	delete(f.entries, req.GetFqdn())
+	return &emptypb.Empty{}, nil
+}
+
+func (f *fakeClient) GetMember(_ context.Context, req *gslbsvc.GetMemberRequest, _ ...grpc.CallOption) (*gslbsvc.GetMemberResponse, error) {
+	for _, member := range f.entries[req.GetFqdn()].GetEntry().GetMembersIpv4() {
+		if member.GetIp() == req.GetIp() {
+			return &gslbsvc.GetMemberResponse{Member: proto.Clone(member).(*entries.Member)}, nil
+		}
+	}
+	return nil, fmt.Errorf("member %s not found", req.GetIp())
+}
+
+func (f *fakeClient) SetMember(_ context.Context, req *gslbsvc.SetMemberRequest, _ ...grpc.CallOption) (*emptypb.Empty, error) {
+	ent, ok := f.entries[req.GetFqdn()]
+	if !ok {
+		return nil, fmt.Errorf("entry %s not found", req.GetFqdn())
+	}
+	members := make([]*entries.Member, 0, len(ent.GetEntry().GetMembersIpv4())+1)
+	for _, member := range ent.GetEntry().GetMembersIpv4() {
+		if member.GetIp() != req.GetMember().GetIp() {
+			members = append(members, member)
+		}
+	}
+	ent.Entry.MembersIpv4 = append(members, proto.Clone(req.GetMember()).(*entries.Member))
+	return &emptypb.Empty{}, nil
+}
+
+func (f *fakeClient) DeleteMember(_ context.Context, req *gslbsvc.DeleteMemberRequest, _ ...grpc.CallOption) (*emptypb.Empty, error) {
+	ent, ok := f.entries[req.GetFqdn()]
+	if !ok {
+		return nil, fmt.Errorf("entry %s not found", req.GetFqdn())
+	}
+	members := make([]*entries.Member, 0, len(ent.GetEntry().GetMembersIpv4()))
+	for _, member := range ent.GetEntry().GetMembersIpv4() {
+		if member.GetIp() != req.GetIp() {
+			members = append(members, member)
+		}
+	}
+	if len(members) == len(ent.GetEntry().GetMembersIpv4()) {
+		return nil, fmt.Errorf("member %s not found", req.GetIp())
+	}
+	ent.Entry.MembersIpv4 = members
+	return &emptypb.Empty{}, nil
+}
+
+func (f *fakeClient) ListMembers(_ context.Context, req *gslbsvc.ListMembersRequest, _ ...grpc.CallOption) (*gslbsvc.ListMembersResponse, error) {
+	ent, ok := f.entries[req.GetFqdn()]
+	if !ok {
+		return nil, fmt.Errorf("entry %s not found", req.GetFqdn())
+	}
+	return &gslbsvc.ListMembersResponse{
+		MembersIpv4: ent.GetEntry().GetMembersIpv4(),
+		MembersIpv6: ent.GetEntry().GetMembersIpv6(),
+	}, nil
+}
+
+func (f *fakeClient) ListEntries(_ context.Context, req *gslbsvc.ListEntriesRequest, _ ...grpc.CallOption) (*gslbsvc.ListEntriesResponse, error) {
+	ents := make([]*gslbsvc.GetEntryResponse, 0, len(f.entries))
+	for fqdn, ent := range f.entries {
+		if strings.HasPrefix(fqdn, req.GetPrefix()) {
+			ents = append(ents, proto.Clone(ent).(*gslbsvc.GetEntryResponse))
+		}
+	}
+	sort.Slice(ents, func(i, j int) bool {
+		return ents[i].GetEntry().GetFqdn() < ents[j].GetEntry().GetFqdn()
+	})
+	return &gslbsvc.ListEntriesResponse{Entries: ents}, nil
+}
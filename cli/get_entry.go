@@ -8,7 +8,8 @@ import (
 
 type GetEntry struct {
 	FQDN *FQDN `positional-args:"true" positional-arg-name:"'fqdn'" required:"true"`
-	Json bool  `short:"j" long:"json" description:"Format in json instead of human table readable."`
+
+	OutputFormat
 
 	client gslbsvc.GSLBClient
 }
@@ -30,11 +31,9 @@ func (c *GetEntry) Execute([]string) error {
 	if err != nil {
 		return err
 	}
-	if c.Json {
-		return PrintProtoJson(entResp)
-	}
-
-	return PrintProtoHuman(entResp)
+	return c.Print(entResp, []string{entResp.GetEntry().GetFqdn()}, func(bool) error {
+		return PrintProtoHuman(entResp)
+	})
 }
 
 func init() {
@@ -8,7 +8,9 @@ import (
 
 type GetEntryStatus struct {
 	FQDN *FQDN `positional-args:"true" positional-arg-name:"'fqdn'" required:"true"`
-	Json bool  `short:"j" long:"json" description:"Format in json instead of human table readable."`
+
+	OutputFormat
+	WatchOptions
 
 	client gslbsvc.GSLBClient
 }
@@ -24,17 +26,43 @@ func (c *GetEntryStatus) Execute([]string) error {
 	msg.Infof("Entry %s configuration", msg.Cyan(c.FQDN))
 	msg.Printf("━━━━━\n")
 	msg.UseStdout()
+	if c.Watch {
+		return c.Run(c.OutputFormat, c.fetch, func(ents []*gslbsvc.GetEntryStatusResponse, transitions map[string]string) error {
+			return c.printTable(ents[0], transitions)
+		})
+	}
 	entResp, err := c.client.GetEntryStatus(context.Background(), &gslbsvc.GetEntryStatusRequest{
 		Fqdn: c.FQDN.String(),
 	})
 	if err != nil {
 		return err
 	}
-	if c.Json {
-		return PrintProtoJson(entResp)
+	return c.Print(entResp, []string{entResp.GetFqdn()}, func(bool) error {
+		return PrintProtoHuman(entResp)
+	})
+}
+
+func (c *GetEntryStatus) fetch(ctx context.Context) ([]*gslbsvc.GetEntryStatusResponse, error) {
+	entResp, err := c.client.GetEntryStatus(ctx, &gslbsvc.GetEntryStatusRequest{
+		Fqdn: c.FQDN.String(),
+	})
+	if err != nil {
+		return nil, err
 	}
+	return []*gslbsvc.GetEntryStatusResponse{entResp}, nil
+}
 
-	return PrintProtoHuman(entResp)
+func (c *GetEntryStatus) printTable(entResp *gslbsvc.GetEntryStatusResponse, transitions map[string]string) error {
+	table := MakeTableWriter([]string{"DC", "Member"})
+	table.SetAutoWrapText(false)
+	for _, member := range entryMembersStatus(entResp) {
+		table.Append([]string{
+			member.GetDc(),
+			MemberStatusText(member) + TransitionText(transitions, entResp.GetFqdn(), member.GetIp()),
+		})
+	}
+	table.Render()
+	return nil
 }
 
 func init() {
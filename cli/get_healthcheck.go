@@ -8,7 +8,8 @@ import (
 
 type GetHealthcheck struct {
 	FQDN *FQDN `positional-args:"true" positional-arg-name:"'fqdn'" required:"true"`
-	Json bool  `short:"j" long:"json" description:"Format in json instead of human table readable."`
+
+	OutputFormat
 
 	client gslbsvc.GSLBClient
 }
@@ -30,19 +31,19 @@ func (c *GetHealthcheck) Execute([]string) error {
 	if err != nil {
 		return err
 	}
-	if c.Json {
-		return PrintProtoJson(entResp)
-	}
-
-	return PrintProtoHuman(entResp)
+	return c.Print(entResp, []string{c.FQDN.String()}, func(bool) error {
+		return PrintProtoHuman(entResp)
+	})
 }
 
 func init() {
 	desc := "Get healthcheck."
+	long := desc + "\nTo run a healthcheck from this host against members before setting it, " +
+		"use test-healthcheck (alias thc) command, e.g.: gsloc-cli test-healthcheck my.fqdn.com. -f healthcheck.yml."
 	cmd, err := parser.AddCommand(
 		"healthcheck",
 		desc,
-		desc,
+		long,
 		&getHealthcheck)
 	if err != nil {
 		panic(err)
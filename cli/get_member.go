@@ -8,9 +8,10 @@ import (
 
 type GetMember struct {
 	FQDN *FQDN  `positional-args:"true" positional-arg-name:"'fqdn'" required:"true"`
-	Json bool   `short:"j" long:"json" description:"Format in json instead of human table readable."`
 	Ip   string `short:"i" long:"ip" description:"IP of the member to get." required:"true"`
 
+	OutputFormat
+
 	client gslbsvc.GSLBClient
 }
 
@@ -32,11 +33,9 @@ func (c *GetMember) Execute([]string) error {
 	if err != nil {
 		return err
 	}
-	if c.Json {
-		return PrintProtoJson(entResp)
-	}
-
-	return PrintProtoHuman(entResp)
+	return c.Print(entResp, []string{entResp.GetMember().GetIp()}, func(bool) error {
+		return PrintProtoHuman(entResp)
+	})
 }
 
 func init() {
@@ -0,0 +1,127 @@
+package cli
+
+import (
+	"fmt"
+	msg "github.com/ArthurHlt/messages"
+	"github.com/orange-cloudfoundry/gsloc-cli/app"
+	"strings"
+	"time"
+)
+
+type History struct {
+	FQDN *FQDN `positional-args:"true" positional-arg-name:"'fqdn'"`
+
+	Ip        string        `short:"i" long:"ip" description:"Only show changes on member with this IP."`
+	User      string        `short:"u" long:"user" description:"Only show changes made by this user."`
+	Command   string        `long:"command" description:"Only show changes made by this command (e.g. set-member-status)."`
+	Operation string        `long:"operation" description:"Only show changes made by this operation."`
+	Since     time.Duration `short:"s" long:"since" description:"Only show changes made since this duration (e.g. 24h)."`
+	Failed    bool          `long:"failed" description:"Only show failed changes."`
+	Limit     int           `short:"l" long:"limit" description:"Maximum number of changes to show, most recent are kept, 0 to show all." default:"50"`
+
+	OutputFormat
+}
+
+var history History
+
+func (c *History) Execute([]string) error {
+	journal := app.NewJournal(app.JournalPath(ExpandConfigPath()))
+	records, err := journal.Records()
+	if err != nil {
+		return err
+	}
+	records = c.Filter(records, time.Now())
+	names := make([]string, len(records))
+	for i, record := range records {
+		names[i] = record.Operation
+	}
+	return c.Print(records, names, func(wide bool) error {
+		if len(records) == 0 {
+			msg.Info("No operations recorded.")
+			return nil
+		}
+		c.printTable(records, wide)
+		return nil
+	})
+}
+
+// Filter gives records matching filters, limit is applied on the most recent records.
+func (c *History) Filter(records []*app.AuditRecord, now time.Time) []*app.AuditRecord {
+	filtered := make([]*app.AuditRecord, 0)
+	for _, record := range records {
+		if c.match(record, now) {
+			filtered = append(filtered, record)
+		}
+	}
+	if c.Limit > 0 && len(filtered) > c.Limit {
+		filtered = filtered[len(filtered)-c.Limit:]
+	}
+	return filtered
+}
+
+func (c *History) match(record *app.AuditRecord, now time.Time) bool {
+	switch {
+	case c.User != "" && record.Username != c.User:
+		return false
+	case c.Command != "" && record.Command != c.Command:
+		return false
+	case c.Operation != "" && record.Operation != c.Operation:
+		return false
+	case c.Since > 0 && record.Time.Before(now.Add(-c.Since)):
+		return false
+	case c.Failed && record.Result != app.AuditResultError:
+		return false
+	}
+	if c.FQDN.IsEmpty() && c.Ip == "" {
+		return true
+	}
+	for _, object := range record.Objects {
+		fqdn, ip, _ := strings.Cut(object, " ")
+		if (c.FQDN.IsEmpty() || fqdn == c.FQDN.String()) && (c.Ip == "" || ip == c.Ip) {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *History) printTable(records []*app.AuditRecord, wide bool) {
+	headers := []string{"Time", "Operation", "User", "Command", "Method", "Objects", "Result"}
+	if wide {
+		headers = append(headers, "Target")
+	}
+	table := MakeTableWriter(headers)
+	table.SetAutoWrapText(false)
+	for _, record := range records {
+		result := msg.Green(record.Result).String()
+		if record.Result == app.AuditResultError {
+			result = msg.Red(fmt.Sprintf("%s: %s", record.Result, record.Error)).String()
+		}
+		row := []string{
+			record.Time.Local().Format(time.RFC3339),
+			record.Operation,
+			record.Username,
+			record.Command,
+			record.Method,
+			strings.Join(record.Objects, "\n"),
+			result,
+		}
+		if wide {
+			row = append(row, record.Target)
+		}
+		table.Append(row)
+	}
+	table.Render()
+}
+
+func init() {
+	desc := "Show changes made from this cli on targets, read from local journal."
+	cmd, err := parser.AddCommand(
+		"history",
+		desc,
+		desc,
+		&history)
+	if err != nil {
+		panic(err)
+	}
+	cmd.Aliases = []string{"hist"}
+}
@@ -0,0 +1,266 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"github.com/ArthurHlt/go-flags"
+	msg "github.com/ArthurHlt/messages"
+	"github.com/orange-cloudfoundry/gsloc-cli/app"
+	gslbsvc "github.com/orange-cloudfoundry/gsloc-go-sdk/gsloc/services/gslb/v1"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"gopkg.in/yaml.v3"
+	"os"
+	"strings"
+)
+
+// templateComments explain fields of an entry manifest, enum values and oneof choices are added from descriptors.
+var templateComments = map[protoreflect.FullName]string{
+	"gsloc.services.gslb.v1.SetEntryRequest.entry":       "Dns entry served by gsloc.",
+	"gsloc.services.gslb.v1.SetEntryRequest.healthcheck": "Healthcheck made on each member to know if it can be returned.",
+
+	"gsloc.api.config.entries.v1.Entry.fqdn":                "Fully qualified domain name, set-entry replaces it by fqdn given in command line.",
+	"gsloc.api.config.entries.v1.Entry.lb_algo_preferred":   "Load balancing algorithm used first, TOPOLOGY gives members in datacenter of requester, RATIO uses ratio of members.",
+	"gsloc.api.config.entries.v1.Entry.lb_algo_alternate":   "Load balancing algorithm used when preferred one gives no member.",
+	"gsloc.api.config.entries.v1.Entry.lb_algo_fallback":    "Load balancing algorithm used when alternate one gives no member.",
+	"gsloc.api.config.entries.v1.Entry.max_answer_returned": "Maximum number of ips in a dns answer, 0 to return all healthy members.",
+	"gsloc.api.config.entries.v1.Entry.members_ipv4":        "IPv4 members, e.g.:\n- ip: 192.168.0.1\n  dc: dc1\n  ratio: 1\n  disabled: false",
+	"gsloc.api.config.entries.v1.Entry.members_ipv6":        "IPv6 members, same format as members_ipv4.",
+	"gsloc.api.config.entries.v1.Entry.ttl":                 "TTL in seconds of dns answers.",
+	"gsloc.api.config.entries.v1.Entry.permissions":         "Permissions on entry.",
+	"gsloc.api.config.entries.v1.Entry.tags":                "Tags to find entries, e.g. with entries --tag or delete-entry --tag.",
+
+	"gsloc.api.config.entries.v1.Member.ip":       "IP of member, must be of the family of the list.",
+	"gsloc.api.config.entries.v1.Member.ratio":    "Weight of member when algorithm is RATIO.",
+	"gsloc.api.config.entries.v1.Member.dc":       "Datacenter of member, see datacenters command.",
+	"gsloc.api.config.entries.v1.Member.disabled": "A disabled member is never returned.",
+
+	"gsloc.api.config.healthchecks.v1.HealthCheck.timeout":    "Time to wait for a response, e.g. 10s.",
+	"gsloc.api.config.healthchecks.v1.HealthCheck.interval":   "Time between two healthchecks, must be greater than timeout.",
+	"gsloc.api.config.healthchecks.v1.HealthCheck.port":       "Port checked on members.",
+	"gsloc.api.config.healthchecks.v1.HealthCheck.tls_config": "TLS used to connect to members.",
+
+	"gsloc.api.config.healthchecks.v1.TlsConfig.enable":      "Connect with TLS.",
+	"gsloc.api.config.healthchecks.v1.TlsConfig.ca":          "PEM encoded CA to verify certificates of members.",
+	"gsloc.api.config.healthchecks.v1.TlsConfig.server_name": "Server name sent and verified, empty to use fqdn without trailing dot.",
+
+	"gsloc.api.config.healthchecks.v1.HttpHealthCheck.host":                   "Host header, empty to use fqdn.",
+	"gsloc.api.config.healthchecks.v1.HttpHealthCheck.path":                   "Path requested, required.",
+	"gsloc.api.config.healthchecks.v1.HttpHealthCheck.send":                   "Body sent, e.g. text: foo or binary: <base64>.",
+	"gsloc.api.config.healthchecks.v1.HttpHealthCheck.receive":                "Content expected in response body, same format as send.",
+	"gsloc.api.config.healthchecks.v1.HttpHealthCheck.request_headers_to_add": "Headers added to request, e.g.:\n- header:\n    key: X-Header\n    value: foo\n  append: false",
+	"gsloc.api.config.healthchecks.v1.HttpHealthCheck.expected_statuses":      "Range of status codes seen as healthy.",
+	"gsloc.api.config.healthchecks.v1.HttpHealthCheck.codec_client_type":      "HTTP version used.",
+	"gsloc.api.config.healthchecks.v1.HttpHealthCheck.method":                 "HTTP method used.",
+
+	"gsloc.api.config.healthchecks.v1.TcpHealthCheck.send":    "Payload sent after connection, empty to only check connection.",
+	"gsloc.api.config.healthchecks.v1.TcpHealthCheck.receive": "Payloads expected in response.",
+
+	"gsloc.api.config.healthchecks.v1.GrpcHealthCheck.service_name": "Service name given to grpc.health.v1.Health/Check.",
+	"gsloc.api.config.healthchecks.v1.GrpcHealthCheck.authority":    "Authority header, empty to use fqdn.",
+
+	"gsloc.api.config.healthchecks.v1.IcmpHealthCheck.delay": "Delay between two pings.",
+
+	"gsloc.api.config.healthchecks.v1.UdpHealthCheck.send":         "Payload sent.",
+	"gsloc.api.config.healthchecks.v1.UdpHealthCheck.receive":      "Payloads expected in response.",
+	"gsloc.api.config.healthchecks.v1.UdpHealthCheck.ping_timeout": "Time to wait for a response.",
+	"gsloc.api.config.healthchecks.v1.UdpHealthCheck.delay":        "Delay between two sends.",
+
+	"gsloc.api.config.healthchecks.v1.PluginHealthCheck.name":    "Plugin name, see healthchecks-plugins command.",
+	"gsloc.api.config.healthchecks.v1.PluginHealthCheck.options": "Options given to plugin.",
+}
+
+type InitEntry struct {
+	File flags.Filename `short:"f" long:"file" description:"Path of yml file to create, - to write on stdout" default:"entry.yml"`
+
+	FQDN *FQDN `positional-args:"true" positional-arg-name:"'fqdn'" required:"true"`
+
+	EntryFlags
+
+	Interactive bool `long:"interactive" description:"Ask values, datacenters and plugins are read from target"`
+	Force       bool `long:"force" description:"Overwrite file if it already exists"`
+}
+
+var initEntry InitEntry
+
+func (c *InitEntry) Execute([]string) error {
+	file := string(c.File)
+	if file != "-" && !c.Force {
+		if _, err := os.Stat(file); err == nil {
+			return fmt.Errorf("file %s already exists, use --force to overwrite it", file)
+		}
+	}
+
+	var req *gslbsvc.SetEntryRequest
+	var err error
+	if c.Interactive {
+		req, err = c.ask()
+	} else {
+		req, err = c.makeEntry(c.FQDN.String())
+	}
+	if err != nil {
+		return err
+	}
+
+	setFile := file
+	if file == "-" {
+		setFile = "<file>"
+	}
+	content, err := EntryTemplate(req, fmt.Sprintf(
+		"Entry %s, create or update it with: gslocli set-entry %s -f %s\nJson schema for editors: gslocli schema entry",
+		c.FQDN, c.FQDN, setFile,
+	))
+	if err != nil {
+		return err
+	}
+	if file == "-" {
+		fmt.Print(string(content))
+		return nil
+	}
+	err = os.WriteFile(file, content, 0644)
+	if err != nil {
+		return err
+	}
+	msg.Successf("Entry template written in %s, edit it and run: gslocli set-entry %s -f %s", msg.Cyan(file), c.FQDN, file)
+	return nil
+}
+
+// ask fill flags with answers, datacenters and plugins are fetched from target.
+func (c *InitEntry) ask() (*gslbsvc.SetEntryRequest, error) {
+	clientConn, err := app.CreateConnFromFile(ExpandConfigPath(), opts.Target)
+	if err != nil {
+		return nil, err
+	}
+	defer clientConn.Close() // nolint:errcheck
+	client := app.MakeClient(clientConn)
+
+	dcsResp, err := client.ListDcs(context.Background(), &gslbsvc.ListDcsRequest{})
+	if err != nil {
+		return nil, err
+	}
+	pluginsResp, err := client.ListPluginHealthChecks(context.Background(), &emptypb.Empty{})
+	if err != nil {
+		return nil, err
+	}
+
+	defer c.Cleanup()
+	err = c.EntryFlags.Ask()
+	if err != nil {
+		return nil, err
+	}
+	members, err := AskMembers(dcsResp.GetDcs(), nil)
+	if err != nil {
+		return nil, err
+	}
+	err = c.HealthcheckFlags.Ask(pluginsResp.GetPluginHealthChecks())
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := c.makeEntry(c.FQDN.String())
+	if err != nil {
+		return nil, err
+	}
+	setMembers(req.Entry, members)
+	return req, nil
+}
+
+// EntryTemplate gives a yml manifest of entry with every field, each field is commented with its meaning.
+func EntryTemplate(req *gslbsvc.SetEntryRequest, header string) ([]byte, error) {
+	data, err := protojson.MarshalOptions{
+		UseProtoNames:   true,
+		EmitUnpopulated: true,
+	}.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+	doc := &yaml.Node{}
+	err = yaml.Unmarshal(data, doc)
+	if err != nil {
+		return nil, err
+	}
+	resetYamlStyle(doc)
+	if len(doc.Content) > 0 {
+		commentYamlFields(doc.Content[0], req.ProtoReflect().Descriptor())
+	}
+	doc.HeadComment = header
+
+	buf := &bytes.Buffer{}
+	enc := yaml.NewEncoder(buf)
+	enc.SetIndent(2)
+	err = enc.Encode(doc)
+	if err != nil {
+		return nil, err
+	}
+	err = enc.Close()
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// resetYamlStyle turn json flow style in yml block style.
+func resetYamlStyle(node *yaml.Node) {
+	node.Style = 0
+	for _, child := range node.Content {
+		resetYamlStyle(child)
+	}
+}
+
+// commentYamlFields set comment of each key of a mapping from message descriptor, only first element of lists is commented.
+func commentYamlFields(node *yaml.Node, md protoreflect.MessageDescriptor) {
+	if node.Kind != yaml.MappingNode {
+		return
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, value := node.Content[i], node.Content[i+1]
+		fd := md.Fields().ByName(protoreflect.Name(key.Value))
+		if fd == nil {
+			continue
+		}
+		key.HeadComment = fieldComment(fd)
+		if fd.Message() == nil || fd.IsMap() {
+			continue
+		}
+		if fd.IsList() {
+			if len(value.Content) > 0 {
+				commentYamlFields(value.Content[0], fd.Message())
+			}
+			continue
+		}
+		commentYamlFields(value, fd.Message())
+	}
+}
+
+func fieldComment(fd protoreflect.FieldDescriptor) string {
+	lines := make([]string, 0)
+	if comment, ok := templateComments[fd.FullName()]; ok {
+		lines = append(lines, comment)
+	}
+	if fd.Enum() != nil {
+		lines = append(lines, "One of: "+strings.Join(enumNames(fd.Enum(), nil), ", ")+".")
+	}
+	if od := fd.ContainingOneof(); od != nil && !od.IsSynthetic() {
+		names := make([]string, od.Fields().Len())
+		for i := range names {
+			names[i] = string(od.Fields().Get(i).Name())
+		}
+		lines = append(lines, "Only one of "+strings.Join(names, ", ")+" can be set.")
+	}
+	return strings.Join(lines, "\n")
+}
+
+func init() {
+	desc := "Create a commented yml definition of an entry to be used by set-entry or apply commands."
+	cmd, err := parser.AddCommand(
+		"init-entry",
+		desc,
+		desc,
+		&initEntry)
+	if err != nil {
+		panic(err)
+	}
+	cmd.Aliases = []string{"ie"}
+}
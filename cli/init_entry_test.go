@@ -0,0 +1,58 @@
+package cli
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/orange-cloudfoundry/gsloc-cli/healthcheck"
+	"github.com/orange-cloudfoundry/gsloc-go-sdk/gsloc/api/config/entries/v1"
+	gslbsvc "github.com/orange-cloudfoundry/gsloc-go-sdk/gsloc/services/gslb/v1"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestEntryTemplate(t *testing.T) {
+	for _, hcType := range []string{"HTTP", "TCP", "UDP", "NO_HEALTHCHECK"} {
+		flags := &EntryFlags{
+			LBAlgoPreferred: "TOPOLOGY",
+			LBAlgoAlternate: "ROUND_ROBIN",
+			LBAlgoFallback:  "RANDOM",
+			TTL:             30,
+			Tags:            []string{"team-a", "123", "true"},
+			HealthcheckFlags: HealthcheckFlags{Builder: healthcheck.Builder{
+				HcTimeout:          "10s",
+				HcInterval:         "30s",
+				HcPort:             443,
+				HcType:             hcType,
+				HttpPath:           "/health",
+				HttpCodeRange:      "200-299",
+				HttpMethod:         "GET",
+				HttpSendPayload:    "ping",
+				TcpReceivePayloads: []string{"pong"},
+				UdpDelay:           "1s",
+				UdpPingTimeout:     "5s",
+			}},
+		}
+		req, err := flags.makeEntry("a.example.com.")
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Entry.MembersIpv4 = []*entries.Member{{Ip: "10.0.0.1", Dc: "dc1", Ratio: 2}}
+
+		content, err := EntryTemplate(req, "header")
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, expected := range []string{"# header\n", "# One of: ROUND_ROBIN, TOPOLOGY, RATIO, RANDOM.\n", "# Only one of http_health_check,"} {
+			if !strings.Contains(string(content), expected) {
+				t.Errorf("Expected template for %s to contain %q, got:\n%s", hcType, expected, content)
+			}
+		}
+		loaded, ok, err := BytesToProto[*gslbsvc.SetEntryRequest](content, "entry.yml")
+		if err != nil || !ok {
+			t.Fatalf("Expected template for %s to be loadable, got loaded %t, err %v:\n%s", hcType, ok, err, content)
+		}
+		if !proto.Equal(loaded, req) {
+			t.Errorf("Template for %s is not the same entry when loaded:\n%s", hcType, content)
+		}
+	}
+}
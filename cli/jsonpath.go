@@ -0,0 +1,147 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// JsonPath is a simple jsonpath made of fields, indexes and wildcards, e.g. `{[*].members_ipv4[0].ip}`,
+// braces and leading dot are optional and a negative index starts from the end.
+// All matching values are written separated by a space, go-template output must be used for anything more complex.
+type JsonPath struct {
+	steps []jsonPathStep
+}
+
+type jsonPathStep struct {
+	name     string
+	index    int
+	isIndex  bool
+	wildcard bool
+}
+
+func ParseJsonPath(tpl string) (*JsonPath, error) {
+	path := strings.TrimSpace(tpl)
+	if strings.HasPrefix(path, "{") && strings.HasSuffix(path, "}") {
+		path = path[1 : len(path)-1]
+	}
+	if strings.ContainsAny(path, "{}()?@'\" ") || strings.Contains(path, "..") {
+		return nil, fmt.Errorf("invalid jsonpath %s: only a path made of fields, indexes and wildcards is supported, use go-template output instead", tpl)
+	}
+	path = strings.TrimPrefix(path, "$")
+	if path != "" && path[0] != '.' && path[0] != '[' {
+		path = "." + path
+	}
+
+	steps := make([]jsonPathStep, 0)
+	for path != "" && path != "." {
+		if strings.HasPrefix(path, ".[") {
+			path = path[1:]
+		}
+		if path[0] == '.' {
+			end := strings.IndexAny(path[1:], ".[") + 1
+			if end == 0 {
+				end = len(path)
+			}
+			name := path[1:end]
+			if name == "" {
+				return nil, fmt.Errorf("invalid jsonpath %s: empty field name", tpl)
+			}
+			steps = append(steps, jsonPathStep{name: name, wildcard: name == "*"})
+			path = path[end:]
+			continue
+		}
+		end := strings.IndexByte(path, ']')
+		if end < 0 {
+			return nil, fmt.Errorf("invalid jsonpath %s: unclosed bracket", tpl)
+		}
+		content := path[1:end]
+		path = path[end+1:]
+		if content == "*" {
+			steps = append(steps, jsonPathStep{wildcard: true})
+			continue
+		}
+		index, err := strconv.Atoi(content)
+		if err != nil {
+			return nil, fmt.Errorf("invalid jsonpath %s: index %s is not a number or *", tpl, content)
+		}
+		steps = append(steps, jsonPathStep{index: index, isIndex: true})
+	}
+	return &JsonPath{steps: steps}, nil
+}
+
+// Execute write values found in data, data must be made of maps, slices and scalars as given by ToGeneric.
+func (j *JsonPath) Execute(w io.Writer, data any) error {
+	values := []any{data}
+	for _, step := range j.steps {
+		next := make([]any, 0)
+		for _, value := range values {
+			next = append(next, step.eval(value)...)
+		}
+		values = next
+	}
+	texts := make([]string, len(values))
+	for i, value := range values {
+		texts[i] = jsonPathValueText(value)
+	}
+	_, err := io.WriteString(w, strings.Join(texts, " "))
+	return err
+}
+
+func (s jsonPathStep) eval(value any) []any {
+	switch {
+	case s.wildcard:
+		return jsonPathChildren(value)
+	case s.isIndex:
+		if l, ok := value.([]any); ok {
+			index := s.index
+			if index < 0 {
+				index += len(l)
+			}
+			if index >= 0 && index < len(l) {
+				return []any{l[index]}
+			}
+		}
+	default:
+		if m, ok := value.(map[string]any); ok {
+			if v, ok := m[s.name]; ok {
+				return []any{v}
+			}
+		}
+	}
+	return nil
+}
+
+func jsonPathValueText(value any) string {
+	if s, ok := value.(string); ok {
+		return s
+	}
+	b, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(b)
+}
+
+// jsonPathChildren gives items of a list or values of a map sorted by keys.
+func jsonPathChildren(value any) []any {
+	switch v := value.(type) {
+	case []any:
+		return v
+	case map[string]any:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		children := make([]any, len(keys))
+		for i, k := range keys {
+			children[i] = v[k]
+		}
+		return children
+	}
+	return nil
+}
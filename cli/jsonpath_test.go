@@ -0,0 +1,75 @@
+package cli
+
+import (
+	"bytes"
+	"testing"
+
+	gslbsvc "github.com/orange-cloudfoundry/gsloc-go-sdk/gsloc/services/gslb/v1"
+)
+
+func TestJsonPath(t *testing.T) {
+	entsStatus := []*gslbsvc.GetEntryStatusResponse{
+		{
+			Fqdn: "a.example.com.",
+			MembersIpv4: []*gslbsvc.MemberStatus{
+				{Ip: "10.0.0.1", Dc: "dc1", Status: gslbsvc.MemberStatus_ONLINE},
+				{Ip: "10.0.0.2", Dc: "dc2", Status: gslbsvc.MemberStatus_OFFLINE},
+			},
+		},
+		{
+			Fqdn: "b.example.com.",
+			MembersIpv4: []*gslbsvc.MemberStatus{
+				{Ip: "10.0.1.1", Dc: "dc1", Status: gslbsvc.MemberStatus_OFFLINE},
+			},
+		},
+	}
+	data, err := ToGeneric(entsStatus)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		template string
+		expected string
+	}{
+		{`{[*].fqdn}`, "a.example.com. b.example.com."},
+		{`[0].fqdn`, "a.example.com."},
+		{`{[-1].members_ipv4[0].dc}`, "dc1"},
+		{`{$[0].members_ipv4[*].ip}`, "10.0.0.1 10.0.0.2"},
+		{`{.[1].members_ipv4.*.status}`, "OFFLINE"},
+		{`{[0].members_ipv4[5].ip}`, ""},
+		{`{[1].members_ipv4}`, `[{"dc":"dc1","failure_reason":"","ip":"10.0.1.1","status":"OFFLINE"}]`},
+	}
+	for _, test := range tests {
+		jsonPath, err := ParseJsonPath(test.template)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %s", test.template, err)
+			continue
+		}
+		buf := &bytes.Buffer{}
+		err = jsonPath.Execute(buf, data)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %s", test.template, err)
+			continue
+		}
+		if buf.String() != test.expected {
+			t.Errorf("%s: expected %q but got %q", test.template, test.expected, buf.String())
+		}
+	}
+
+	for _, template := range []string{
+		`{range [*]}{.fqdn}{end}`,
+		`{[*].members_ipv4[?(@.status=="OFFLINE")].ip}`,
+		`{..ip}`,
+		`{[0:1].fqdn}`,
+		`fqdns: {[*].fqdn}`,
+		`{[abc]}`,
+		`{[0}`,
+		`{[0]..fqdn}`,
+	} {
+		_, err := ParseJsonPath(template)
+		if err == nil {
+			t.Errorf("%s: expected an error", template)
+		}
+	}
+}
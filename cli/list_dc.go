@@ -7,7 +7,7 @@ import (
 )
 
 type ListDcs struct {
-	Json bool `short:"j" long:"json" description:"Format in json instead of human table readable."`
+	OutputFormat
 
 	client gslbsvc.GSLBClient
 }
@@ -24,22 +24,20 @@ func (c *ListDcs) Execute([]string) error {
 		return err
 	}
 
-	if c.Json {
-		return PrintProtoJson(dcsResp)
-	}
-
-	if len(dcsResp.GetDcs()) == 0 {
-		msg.Info("No datacenters found.")
+	return c.Print(dcsResp, dcsResp.GetDcs(), func(bool) error {
+		if len(dcsResp.GetDcs()) == 0 {
+			msg.Info("No datacenters found.")
+			return nil
+		}
+
+		table := MakeTableWriter([]string{"DATACENTER"})
+		table.SetAutoWrapText(false)
+		for _, dc := range dcsResp.GetDcs() {
+			table.Append([]string{dc})
+		}
+		table.Render()
 		return nil
-	}
-
-	table := MakeTableWriter([]string{"DATACENTER"})
-	table.SetAutoWrapText(false)
-	for _, dc := range dcsResp.GetDcs() {
-		table.Append([]string{dc})
-	}
-	table.Render()
-	return nil
+	})
 }
 
 func init() {
@@ -2,14 +2,16 @@ package cli
 
 import (
 	"context"
+	"fmt"
 	msg "github.com/ArthurHlt/messages"
 	"github.com/orange-cloudfoundry/gsloc-go-sdk/gsloc/api/config/entries/v1"
 	hcconf "github.com/orange-cloudfoundry/gsloc-go-sdk/gsloc/api/config/healthchecks/v1"
 	gslbsvc "github.com/orange-cloudfoundry/gsloc-go-sdk/gsloc/services/gslb/v1"
+	"strings"
 )
 
 type ListEntries struct {
-	Json bool `short:"j" long:"json" description:"Format in json instead of human table readable."`
+	OutputFormat
 
 	Tags   []string `short:"t" long:"tag" description:"Filter by tag(s) (can be set multiple times)."`
 	Prefix string   `short:"p" long:"prefix" description:"Filter by prefix."`
@@ -32,11 +34,17 @@ func (c *ListEntries) Execute([]string) error {
 		return err
 	}
 
-	if c.Json {
-		return PrintProtoListJson[*gslbsvc.GetEntryResponse](entsResp.Entries)
+	names := make([]string, len(entsResp.GetEntries()))
+	for i, ent := range entsResp.GetEntries() {
+		names[i] = ent.GetEntry().GetFqdn()
 	}
+	return c.Print(entsResp.GetEntries(), names, func(wide bool) error {
+		return c.printTable(entsResp.GetEntries(), wide)
+	})
+}
 
-	if len(entsResp.Entries) == 0 {
+func (c *ListEntries) printTable(ents []*gslbsvc.GetEntryResponse, wide bool) error {
+	if len(ents) == 0 {
 		msg.Info("No entries found.")
 		return nil
 	}
@@ -46,9 +54,13 @@ func (c *ListEntries) Execute([]string) error {
 		return err
 	}
 
-	table := MakeTableWriter(append([]string{"FQDN", "Healthcheck"}, dcResp.GetDcs()...))
+	headers := []string{"FQDN", "Healthcheck"}
+	if wide {
+		headers = append(headers, "TTL", "LB Algo", "Tags")
+	}
+	table := MakeTableWriter(append(headers, dcResp.GetDcs()...))
 	table.SetAutoWrapText(false)
-	for _, ent := range entsResp.GetEntries() {
+	for _, ent := range ents {
 		line := []string{ent.GetEntry().GetFqdn()}
 		switch ent.GetHealthcheck().HealthChecker.(type) {
 		case *hcconf.HealthCheck_HttpHealthCheck:
@@ -60,6 +72,16 @@ func (c *ListEntries) Execute([]string) error {
 		default:
 			line = append(line, "NONE")
 		}
+		if wide {
+			line = append(line,
+				fmt.Sprintf("%d", ent.GetEntry().GetTtl()),
+				fmt.Sprintf("%s\n%s\n%s",
+					ent.GetEntry().GetLbAlgoPreferred(),
+					ent.GetEntry().GetLbAlgoAlternate(),
+					ent.GetEntry().GetLbAlgoFallback()),
+				strings.Join(ent.GetEntry().GetTags(), "\n"),
+			)
+		}
 
 		for _, dc := range dcResp.GetDcs() {
 			dcContent := c.makeDcContent(ent.GetEntry().GetMembersIpv4(), dc)
@@ -5,11 +5,11 @@ import (
 	"fmt"
 	msg "github.com/ArthurHlt/messages"
 	gslbsvc "github.com/orange-cloudfoundry/gsloc-go-sdk/gsloc/services/gslb/v1"
-	"strings"
 )
 
 type ListEntriesStatus struct {
-	Json bool `short:"j" long:"json" description:"Format in json instead of human table readable."`
+	OutputFormat
+	WatchOptions
 
 	Tags   []string `short:"t" long:"tag" description:"Filter by tag(s) (can be set multiple times)."`
 	Prefix string   `short:"p" long:"prefix" description:"Filter by prefix."`
@@ -24,19 +24,39 @@ func (c *ListEntriesStatus) SetClient(client gslbsvc.GSLBClient) {
 var listEntriesStatus ListEntriesStatus
 
 func (c *ListEntriesStatus) Execute([]string) error {
-	entsResp, err := c.client.ListEntriesStatus(context.Background(), &gslbsvc.ListEntriesStatusRequest{
-		Tags:   c.Tags,
-		Prefix: c.Prefix,
-	})
+	if c.Watch {
+		return c.Run(c.OutputFormat, c.fetch, func(ents []*gslbsvc.GetEntryStatusResponse, transitions map[string]string) error {
+			return c.printTable(ents, c.Format() == OutputWide, transitions)
+		})
+	}
+	entsStatus, err := c.fetch(context.Background())
 	if err != nil {
 		return err
 	}
 
-	if c.Json {
-		return PrintProtoListJson[*gslbsvc.GetEntryStatusResponse](entsResp.GetEntriesStatus())
+	names := make([]string, len(entsStatus))
+	for i, entStatus := range entsStatus {
+		names[i] = entStatus.GetFqdn()
 	}
+	return c.Print(entsStatus, names, func(wide bool) error {
+		return c.printTable(entsStatus, wide, nil)
+	})
+}
+
+func (c *ListEntriesStatus) fetch(ctx context.Context) ([]*gslbsvc.GetEntryStatusResponse, error) {
+	entsResp, err := c.client.ListEntriesStatus(ctx, &gslbsvc.ListEntriesStatusRequest{
+		Tags:   c.Tags,
+		Prefix: c.Prefix,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return entsResp.GetEntriesStatus(), nil
+}
 
-	if len(entsResp.GetEntriesStatus()) == 0 {
+// printTable print entries status by datacenter, members in transitions are highlighted with their previous status.
+func (c *ListEntriesStatus) printTable(entsStatus []*gslbsvc.GetEntryStatusResponse, wide bool, transitions map[string]string) error {
+	if len(entsStatus) == 0 {
 		msg.Info("No entries found.")
 		return nil
 	}
@@ -46,13 +66,27 @@ func (c *ListEntriesStatus) Execute([]string) error {
 		return err
 	}
 
-	table := MakeTableWriter(append([]string{"FQDN"}, dcResp.GetDcs()...))
+	headers := []string{"FQDN"}
+	if wide {
+		headers = append(headers, "Online")
+	}
+	table := MakeTableWriter(append(headers, dcResp.GetDcs()...))
 	table.SetAutoWrapText(false)
-	for _, entStatus := range entsResp.GetEntriesStatus() {
+	for _, entStatus := range entsStatus {
 		line := []string{entStatus.GetFqdn()}
+		if wide {
+			members := entryMembersStatus(entStatus)
+			nbOnline := 0
+			for _, member := range members {
+				if member.GetStatus() == gslbsvc.MemberStatus_ONLINE {
+					nbOnline++
+				}
+			}
+			line = append(line, fmt.Sprintf("%d/%d", nbOnline, len(members)))
+		}
 		for _, dc := range dcResp.GetDcs() {
-			dcContent := c.makeDcContent(entStatus.GetMembersIpv4(), dc)
-			dcContent += "\n" + c.makeDcContent(entStatus.GetMembersIpv6(), dc)
+			dcContent := c.makeDcContent(entStatus.GetFqdn(), entStatus.GetMembersIpv4(), dc, transitions)
+			dcContent += "\n" + c.makeDcContent(entStatus.GetFqdn(), entStatus.GetMembersIpv6(), dc, transitions)
 			line = append(line, dcContent)
 		}
 		table.Append(line)
@@ -61,31 +95,32 @@ func (c *ListEntriesStatus) Execute([]string) error {
 	return nil
 }
 
-func (c *ListEntriesStatus) makeDcContent(entMemberStatus []*gslbsvc.MemberStatus, dc string) string {
+func (c *ListEntriesStatus) makeDcContent(fqdn string, entMemberStatus []*gslbsvc.MemberStatus, dc string, transitions map[string]string) string {
 	dcContent := ""
 	for _, entMemberStatus := range entMemberStatus {
 		if entMemberStatus.GetDc() != dc {
 			continue
 		}
-		if entMemberStatus.GetStatus() == gslbsvc.MemberStatus_ONLINE {
-			dcContent += msg.Green(entMemberStatus.GetIp()).String() + " (Online)\n"
-			continue
-		}
-		if entMemberStatus.GetStatus() == gslbsvc.MemberStatus_OFFLINE {
-			dcContent += msg.Red(entMemberStatus.GetIp()).String() + " (Offline)\n"
-			continue
-		}
-		reason := entMemberStatus.GetFailureReason()
-		if entMemberStatus.GetStatus() == gslbsvc.MemberStatus_CHECK_FAILED && strings.Contains(reason, "disabled entry") {
-			dcContent += msg.Yellow(entMemberStatus.GetIp()).String() + " (Disabled by User)\n"
-			continue
-		}
-		dcContent += msg.Red(entMemberStatus.GetIp()).String() +
-			fmt.Sprintf(" (Check failed: %s)\n", entMemberStatus.GetFailureReason())
+		dcContent += MemberStatusText(entMemberStatus) + TransitionText(transitions, fqdn, entMemberStatus.GetIp()) + "\n"
 	}
 	return dcContent
 }
 
+// MemberStatusText gives member ip colored by status followed by status in a human readable form.
+func MemberStatusText(entMemberStatus *gslbsvc.MemberStatus) string {
+	if entMemberStatus.GetStatus() == gslbsvc.MemberStatus_ONLINE {
+		return msg.Green(entMemberStatus.GetIp()).String() + " (Online)"
+	}
+	if entMemberStatus.GetStatus() == gslbsvc.MemberStatus_OFFLINE {
+		return msg.Red(entMemberStatus.GetIp()).String() + " (Offline)"
+	}
+	if IsDisabledByUser(entMemberStatus) {
+		return msg.Yellow(entMemberStatus.GetIp()).String() + " (Disabled by User)"
+	}
+	return msg.Red(entMemberStatus.GetIp()).String() +
+		fmt.Sprintf(" (Check failed: %s)", entMemberStatus.GetFailureReason())
+}
+
 func init() {
 	desc := "List entries with status."
 	cmd, err := parser.AddCommand(
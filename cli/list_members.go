@@ -11,7 +11,8 @@ import (
 
 type ListMembers struct {
 	FQDN *FQDN `positional-args:"true" positional-arg-name:"'fqdn'" required:"true"`
-	Json bool  `short:"j" long:"json" description:"Format in json instead of human table readable."`
+
+	OutputFormat
 
 	client gslbsvc.GSLBClient
 }
@@ -33,15 +34,20 @@ func (c *ListMembers) Execute([]string) error {
 	if err != nil {
 		return err
 	}
-	if c.Json {
-		return PrintProtoJson(entResp)
+	names := make([]string, 0)
+	for _, members := range [][]*entries.Member{entResp.GetMembersIpv4(), entResp.GetMembersIpv6()} {
+		for _, member := range members {
+			names = append(names, member.GetIp())
+		}
 	}
-	table := MakeTableWriter([]string{"DC", "IP", "Ratio", "State"})
-	table.SetAutoWrapText(false)
-	c.addToTable(table, entResp.GetMembersIpv4())
-	c.addToTable(table, entResp.GetMembersIpv6())
-	table.Render()
-	return nil
+	return c.Print(entResp, names, func(bool) error {
+		table := MakeTableWriter([]string{"DC", "IP", "Ratio", "State"})
+		table.SetAutoWrapText(false)
+		c.addToTable(table, entResp.GetMembersIpv4())
+		c.addToTable(table, entResp.GetMembersIpv6())
+		table.Render()
+		return nil
+	})
 }
 
 func (c *ListMembers) addToTable(table *tablewriter.Table, members []*entries.Member) {
@@ -9,7 +9,7 @@ import (
 )
 
 type ListPlugins struct {
-	Json bool `short:"j" long:"json" description:"Format in json instead of human table readable."`
+	OutputFormat
 
 	client gslbsvc.GSLBClient
 }
@@ -26,27 +26,29 @@ func (c *ListPlugins) Execute([]string) error {
 		return err
 	}
 
-	if c.Json {
-		return PrintProtoJson(plugResp)
-	}
-
-	if len(plugResp.GetPluginHealthChecks()) == 0 {
-		msg.Info("No plugins found.")
-		return nil
-	}
-
 	plugins := plugResp.GetPluginHealthChecks()
 	sort.Slice(plugins, func(i, j int) bool {
 		return plugins[i].GetName() < plugins[j].GetName()
 	})
-
-	table := MakeTableWriter([]string{"Name", "Description"})
-	table.SetAutoWrapText(false)
-	for _, plugin := range plugins {
-		table.Append([]string{plugin.GetName(), plugin.GetDescription()})
+	names := make([]string, len(plugins))
+	for i, plugin := range plugins {
+		names[i] = plugin.GetName()
 	}
-	table.Render()
-	return nil
+
+	return c.Print(plugResp, names, func(bool) error {
+		if len(plugins) == 0 {
+			msg.Info("No plugins found.")
+			return nil
+		}
+
+		table := MakeTableWriter([]string{"Name", "Description"})
+		table.SetAutoWrapText(false)
+		for _, plugin := range plugins {
+			table.Append([]string{plugin.GetName(), plugin.GetDescription()})
+		}
+		table.Render()
+		return nil
+	})
 }
 
 func init() {
@@ -11,6 +11,8 @@ type LoginUser struct {
 	Host              string `short:"t" long:"host" description:"Host of gsloc" env:"GSLOC_HOST"`
 	Username          string `short:"u" long:"username" description:"Username" env:"GSLOC_USERNAME"`
 	Password          string `short:"p" long:"password" description:"Password" env:"GSLOC_PASSWORD"`
+	TokenURL          string `short:"a" long:"token-url" description:"Url of the oauth2 token endpoint used to authenticate" env:"GSLOC_TOKEN_URL"`
+	ClientID          string `long:"client-id" description:"Oauth2 client id used to authenticate (default: gsloc-cli)" env:"GSLOC_CLIENT_ID"`
 	SkipSslValidation bool   `short:"k" long:"skip-ssl-validation" description:"Skip SSL validation"`
 }
 
@@ -21,7 +23,7 @@ func (c *LoginUser) Execute([]string) error {
 	if c.Host != "" && len(hostSplit) == 1 {
 		c.Host = c.Host + ":443"
 	}
-	currentUsername := app.GetCurrentUsername(ExpandConfigPath())
+	currentUsername := app.GetCurrentUsername(ExpandConfigPath(), opts.Target)
 	var qs []*survey.Question
 	if c.Username == "" {
 		qs = append(qs, &survey.Question{
@@ -40,9 +42,21 @@ func (c *LoginUser) Execute([]string) error {
 			},
 		})
 	}
+	// targets made before token url was configurable don't have one
+	if c.TokenURL == "" && currentTokenURL() == "" {
+		qs = append(qs, &survey.Question{
+			Name: "tokenurl",
+			Prompt: &survey.Input{
+				Message: "Token url:",
+				Help:    "Url of the oauth2 token endpoint used to authenticate, it can be set with --token-url.",
+			},
+			Validate: survey.Required,
+		})
+	}
 	answers := struct {
 		Username string
 		Password string
+		TokenURL string `survey:"tokenurl"`
 	}{}
 	if len(qs) > 0 {
 		// perform the questions
@@ -56,16 +70,40 @@ func (c *LoginUser) Execute([]string) error {
 		if answers.Password != "" {
 			c.Password = answers.Password
 		}
+		if answers.TokenURL != "" {
+			c.TokenURL = answers.TokenURL
+		}
 	}
-	_, err := app.CreateConn(ExpandConfigPath(), c.Host, c.Username, c.Password, c.SkipSslValidation)
+	conn, err := app.CreateConn(ExpandConfigPath(), app.Target{
+		Name:       opts.Target,
+		Host:       c.Host,
+		Username:   c.Username,
+		SkipVerify: c.SkipSslValidation,
+		TokenURL:   c.TokenURL,
+		ClientID:   c.ClientID,
+	}, c.Password)
 	if err != nil {
 		return err
 	}
-	msg.Success("Login successful.")
+	conn.Close() // nolint:errcheck
+	target, err := app.GetTarget(ExpandConfigPath(), "")
+	if err != nil {
+		return err
+	}
+	msg.Successf("Login successful on target %s.", msg.Cyan(target.Name))
 	return nil
 
 }
 
+// currentTokenURL gives token url of target to log in, empty when target does not exist yet.
+func currentTokenURL() string {
+	target, err := app.GetTarget(ExpandConfigPath(), opts.Target)
+	if err != nil {
+		return ""
+	}
+	return target.TokenURL
+}
+
 func init() {
 	desc := "Login"
 	cmd, err := parser.AddCommand(
@@ -15,9 +15,21 @@ const defaultConfigPath = "~/.gsloc/config.json"
 
 type Options struct {
 	ConfigPath string `short:"c" long:"config" description:"Path to config file" default:"~/.gsloc/config.json" env:"GSLOC_CONFIG_PATH"`
+	Target     string `          long:"target" description:"Name of the target to use instead of the current one" env:"GSLOC_TARGET"`
+	DiffFormat string `          long:"diff-format" description:"Format of changes shown before applying or by diff command" choice:"human" choice:"json" choice:"unified" choice:"gopatch" default:"human" env:"GSLOC_DIFF_FORMAT"`
 	Version    func() `          long:"version" description:"Show version"`
 }
 
+// ExitError let a command exit with a specific exit code, message is not shown when empty.
+type ExitError struct {
+	Code    int
+	Message string
+}
+
+func (e *ExitError) Error() string {
+	return e.Message
+}
+
 type SetClient interface {
 	SetClient(client gslbsvc.GSLBClient)
 }
@@ -41,11 +53,11 @@ func Start(version, commit, date string) (err error) {
 	parser.CommandHandler = func(command flags.Commander, args []string) error {
 		msg.UseStdout()
 		if cmd, ok := command.(SetClient); ok {
-			clientConn, err = app.CreateConnFromFile(ExpandConfigPath())
+			clientConn, err = app.CreateConnFromFile(ExpandConfigPath(), opts.Target)
 			if err != nil {
 				return err
 			}
-			cmd.SetClient(app.MakeClient(clientConn))
+			cmd.SetClient(auditClient(app.MakeClient(clientConn)))
 		}
 
 		return command.Execute(args)
@@ -0,0 +1,111 @@
+package cli
+
+import (
+	"fmt"
+	"google.golang.org/protobuf/proto"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+var yamlDocSeparatorRegex = regexp.MustCompile(`(?m)^---[ \t]*(#.*)?$`)
+
+type Manifest[T proto.Message] struct {
+	// Source is the file path followed by document index for multi-document yaml (e.g.: entries.yml#2)
+	Source string
+	// Line is the line in file where the document starts, line n of document is line Line+n-1 in file
+	Line    int
+	Content []byte
+	Msg     T
+}
+
+// ManifestFiles gives all json and yaml files in a path, path can be a single file or a directory
+// which is walked recursively.
+func ManifestFiles(path string) ([]string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return []string{path}, nil
+	}
+	files := make([]string, 0)
+	err = filepath.WalkDir(path, func(p string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		if !IsYamlFile(p) && strings.ToLower(filepath.Ext(p)) != ".json" {
+			return nil
+		}
+		files = append(files, p)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// SplitYamlDocuments split a multi-document yaml, the start line of each document is given as well.
+func SplitYamlDocuments(content []byte) ([][]byte, []int) {
+	docs := make([][]byte, 0)
+	lines := make([]int, 0)
+	prev := 0
+	prevLine := 1
+	for _, loc := range yamlDocSeparatorRegex.FindAllIndex(content, -1) {
+		docs = append(docs, content[prev:loc[0]])
+		lines = append(lines, prevLine)
+		prevLine += strings.Count(string(content[prev:loc[1]]), "\n")
+		prev = loc[1]
+	}
+	docs = append(docs, content[prev:])
+	lines = append(lines, prevLine)
+	return docs, lines
+}
+
+// LoadManifests load every json and yaml (including multi-document yaml) in a file or a directory.
+// Empty documents are skipped.
+func LoadManifests[T proto.Message](path string) ([]*Manifest[T], error) {
+	files, err := ManifestFiles(path)
+	if err != nil {
+		return nil, err
+	}
+	manifests := make([]*Manifest[T], 0)
+	for _, file := range files {
+		content, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read file %s: %w", file, err)
+		}
+		docs := [][]byte{content}
+		lines := []int{1}
+		if IsYamlFile(file) {
+			docs, lines = SplitYamlDocuments(content)
+		}
+		for i, doc := range docs {
+			source := file
+			if len(docs) > 1 {
+				source = fmt.Sprintf("%s#%d", file, i+1)
+			}
+			pMsg, loaded, err := BytesToProto[T](doc, file)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", source, err)
+			}
+			if !loaded {
+				continue
+			}
+			manifests = append(manifests, &Manifest[T]{
+				Source:  source,
+				Line:    lines[i],
+				Content: doc,
+				Msg:     pMsg,
+			})
+		}
+	}
+	return manifests, nil
+}
@@ -0,0 +1,150 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ArthurHlt/go-flags"
+	"github.com/orange-cloudfoundry/gsloc-go-sdk/gsloc/api/config/entries/v1"
+	gslbsvc "github.com/orange-cloudfoundry/gsloc-go-sdk/gsloc/services/gslb/v1"
+)
+
+func TestSplitYamlDocuments(t *testing.T) {
+	docs, lines := SplitYamlDocuments([]byte("a: 1\n---\nb: 2\nc: 3\n--- # comment\nd: 4\n"))
+	if len(docs) != 3 {
+		t.Fatalf("Expected 3 documents but got %d", len(docs))
+	}
+	expectedLines := []int{1, 2, 5}
+	for i, line := range expectedLines {
+		if lines[i] != line {
+			t.Errorf("Expected document %d to start at line %d but got %d", i, line, lines[i])
+		}
+	}
+	if string(docs[1]) != "\nb: 2\nc: 3\n" {
+		t.Errorf("Unexpected second document content: %q", string(docs[1]))
+	}
+}
+
+func TestLoadEntryManifests(t *testing.T) {
+	dir := t.TempDir()
+	err := os.WriteFile(filepath.Join(dir, "entries.yml"), []byte(`
+entry:
+  fqdn: Foo.Example.com
+---
+---
+entry:
+  fqdn: bar.example.com.
+`), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = os.WriteFile(filepath.Join(dir, "other.json"), []byte(`{"entry": {"fqdn": "baz.example.com"}}`), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = os.WriteFile(filepath.Join(dir, "README.md"), []byte(`not a manifest`), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	manifests, err := LoadEntryManifests(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{"foo.example.com.", "bar.example.com.", "baz.example.com."}
+	if len(manifests) != len(expected) {
+		t.Fatalf("Expected %d manifests but got %d", len(expected), len(manifests))
+	}
+	for i, fqdn := range expected {
+		if manifests[i].Msg.GetEntry().GetFqdn() != fqdn {
+			t.Errorf("Expected fqdn %s but got %s", fqdn, manifests[i].Msg.GetEntry().GetFqdn())
+		}
+	}
+	if manifests[1].Source != filepath.Join(dir, "entries.yml")+"#3" {
+		t.Errorf("Unexpected source %s", manifests[1].Source)
+	}
+
+	changes, err := EntriesChanges(manifests, []*gslbsvc.GetEntryResponse{
+		{Entry: manifests[0].Msg.GetEntry(), Healthcheck: manifests[0].Msg.GetHealthcheck()},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != 2 || changes[0].Action != changeCreate {
+		t.Errorf("Expected 2 entries to create but got %d changes", len(changes))
+	}
+}
+
+func TestLoadEntryManifestsDuplicate(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "entries.yml")
+	err := os.WriteFile(file, []byte("entry:\n  fqdn: foo.com\n---\nentry:\n  fqdn: FOO.com.\n"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = LoadEntryManifests(file)
+	if err == nil {
+		t.Errorf("Expected error for duplicated entry")
+	}
+}
+
+func TestBytesToProtoNestedEmptyObject(t *testing.T) {
+	manifest := []byte(`entry:
+  fqdn: a.example.com.
+  ttl: 30
+healthcheck:
+  no_health_check: {}
+`)
+	req, loaded, err := BytesToProto[*gslbsvc.SetEntryRequest](manifest, "entry.yml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !loaded {
+		t.Fatal("Expected manifest with a nested empty object to be loaded")
+	}
+	if req.GetEntry().GetFqdn() != "a.example.com." || req.GetHealthcheck().GetNoHealthCheck() == nil {
+		t.Errorf("Unexpected request %v", req)
+	}
+
+	for _, content := range []string{"{}", " { }\n", "", "null"} {
+		_, loaded, err = BytesToProto[*gslbsvc.SetEntryRequest]([]byte(content), "entry.yml")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if loaded {
+			t.Errorf("Expected %q to be considered empty", content)
+		}
+	}
+}
+
+func TestApplyManagedTag(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "entries.yml")
+	err := os.WriteFile(file, []byte("entry:\n  fqdn: foo.example.com.\n  ttl: 30\n"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := &fakeClient{entries: map[string]*gslbsvc.GetEntryResponse{
+		"foo.example.com.": {Entry: &entries.Entry{Fqdn: "foo.example.com.", Ttl: 30}},
+	}}
+
+	err = (&Apply{File: flags.Filename(file), Force: true, client: client}).Execute(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tags := client.entries["foo.example.com."].GetEntry().GetTags(); len(tags) != 0 {
+		t.Errorf("Expected no tag to be added without --managed-tag, got %v", tags)
+	}
+
+	err = (&Apply{File: flags.Filename(file), Prune: true, Force: true, client: client}).Execute(nil)
+	if err == nil {
+		t.Error("Expected an error when pruning without managed tag")
+	}
+
+	err = (&Apply{File: flags.Filename(file), ManagedTag: "managed-by-gslocli", Force: true, client: client}).Execute(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tags := client.entries["foo.example.com."].GetEntry().GetTags(); len(tags) != 1 || tags[0] != "managed-by-gslocli" {
+		t.Errorf("Expected managed tag to be added, got %v", tags)
+	}
+}
@@ -0,0 +1,422 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/ArthurHlt/go-flags"
+	msg "github.com/ArthurHlt/messages"
+	gslbsvc "github.com/orange-cloudfoundry/gsloc-go-sdk/gsloc/services/gslb/v1"
+	"gopkg.in/yaml.v3"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"os/signal"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	RuleNoOnline         = "no_online"
+	RuleMinOnlinePercent = "min_online_percent"
+	RuleMemberStatus     = "member_status"
+
+	AlertFiring   = "firing"
+	AlertResolved = "resolved"
+
+	// allDcs as rule dc evaluates each datacenter of an entry separately
+	allDcs = "*"
+
+	notifyTimeout = 30 * time.Second
+)
+
+// MonitorConfig is the yml file given to monitor command.
+type MonitorConfig struct {
+	Rules    []*MonitorRule `yaml:"rules"`
+	Webhooks []string       `yaml:"webhooks"`
+	Commands []string       `yaml:"commands"`
+}
+
+// MonitorRule is a condition on entries status, it fires when condition holds during For.
+// Members disabled by user are ignored by no_online and min_online_percent rules.
+type MonitorRule struct {
+	Name string `yaml:"name"`
+	Type string `yaml:"type"`
+	// Prefix filters entries by fqdn prefix
+	Prefix string `yaml:"prefix"`
+	// Dc restricts rule to members of a datacenter, * evaluates each datacenter separately, empty for all members
+	Dc string `yaml:"dc"`
+	// Percent is the minimum percent of ONLINE members for min_online_percent
+	Percent float64 `yaml:"percent"`
+	// Status is the status of member which fires member_status, DISABLED for members disabled by user
+	Status string        `yaml:"status"`
+	For    time.Duration `yaml:"for"`
+}
+
+// AlertEvent is sent to notifiers when an alert fires or resolves, Since is when condition started to hold.
+type AlertEvent struct {
+	Time    time.Time `json:"time"`
+	Rule    string    `json:"rule"`
+	State   string    `json:"state"`
+	Fqdn    string    `json:"fqdn"`
+	Dc      string    `json:"dc,omitempty"`
+	Ip      string    `json:"ip,omitempty"`
+	Message string    `json:"message"`
+	Since   time.Time `json:"since"`
+}
+
+// alertCondition is a rule holding for an entry, a datacenter of entry or a member.
+type alertCondition struct {
+	rule    *MonitorRule
+	fqdn    string
+	dc      string
+	ip      string
+	message string
+}
+
+func (a *alertCondition) key() string {
+	return strings.Join([]string{a.rule.Name, a.fqdn, a.dc, a.ip}, " ")
+}
+
+type Monitor struct {
+	Rules    flags.Filename `short:"r" long:"rules" description:"Path to yml file with rules, webhooks and commands" required:"true"`
+	Interval time.Duration  `short:"n" long:"interval" description:"Interval between two evaluations of rules." default:"30s"`
+
+	Webhooks []string `long:"webhook" description:"Url where events are posted in json, added to webhooks of rules file (can be set multiple times)."`
+	Commands []string `long:"exec" description:"Shell command run on each event with event in json on stdin and GSLOC_ALERT_* env vars, added to commands of rules file (can be set multiple times)."`
+
+	Tags   []string `short:"t" long:"tag" description:"Filter by tag(s) (can be set multiple times)."`
+	Prefix string   `short:"p" long:"prefix" description:"Filter by prefix."`
+
+	client gslbsvc.GSLBClient
+
+	config *MonitorConfig
+	// pending holds conditions currently holding with time they started to hold
+	pending map[string]time.Time
+	firing  map[string]*AlertEvent
+}
+
+var monitor Monitor
+
+func (c *Monitor) SetClient(client gslbsvc.GSLBClient) {
+	c.client = client
+}
+
+func (c *Monitor) Execute([]string) error {
+	if c.Interval <= 0 {
+		return fmt.Errorf("interval must be positive")
+	}
+	config, err := LoadMonitorConfig(string(c.Rules))
+	if err != nil {
+		return err
+	}
+	config.Webhooks = append(config.Webhooks, c.Webhooks...)
+	config.Commands = append(config.Commands, c.Commands...)
+	c.config = config
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer cancel()
+	msg.UseStderr()
+	msg.Infof("Evaluating %d rules every %s, events are written on stdout (ctrl+c to quit)", len(config.Rules), c.Interval)
+	msg.UseStdout()
+	for {
+		resp, err := c.client.ListEntriesStatus(ctx, &gslbsvc.ListEntriesStatusRequest{
+			Tags:   c.Tags,
+			Prefix: c.Prefix,
+		})
+		if ctx.Err() != nil {
+			return nil
+		}
+		if err != nil {
+			// alerts are kept as is when status can't be retrieved
+			msg.UseStderr()
+			msg.Error(fmt.Sprintf("Failed to get status: %s", err.Error()))
+			msg.UseStdout()
+		} else {
+			for _, event := range c.Evaluate(resp.GetEntriesStatus(), time.Now()) {
+				c.notify(ctx, event)
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(c.Interval):
+		}
+	}
+}
+
+// LoadMonitorConfig read and check rules file, unknown keys are refused to catch typos.
+func LoadMonitorConfig(file string) (*MonitorConfig, error) {
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	config := &MonitorConfig{}
+	dec := yaml.NewDecoder(bytes.NewReader(content))
+	dec.KnownFields(true)
+	err = dec.Decode(config)
+	if err != nil && err != io.EOF {
+		return nil, fmt.Errorf("%s: %s", file, err)
+	}
+	if len(config.Rules) == 0 {
+		return nil, fmt.Errorf("%s: no rules found", file)
+	}
+	names := make(map[string]bool)
+	for i, rule := range config.Rules {
+		if rule.Name == "" {
+			return nil, fmt.Errorf("%s: rule %d: name is required", file, i+1)
+		}
+		if names[rule.Name] {
+			return nil, fmt.Errorf("%s: rule %s: name is already used", file, rule.Name)
+		}
+		names[rule.Name] = true
+		err = rule.check()
+		if err != nil {
+			return nil, fmt.Errorf("%s: rule %s: %s", file, rule.Name, err)
+		}
+	}
+	return config, nil
+}
+
+func (r *MonitorRule) check() error {
+	if r.For < 0 {
+		return fmt.Errorf("for must be positive")
+	}
+	switch r.Type {
+	case RuleNoOnline:
+	case RuleMinOnlinePercent:
+		if r.Percent <= 0 || r.Percent > 100 {
+			return fmt.Errorf("percent must be between 0 and 100")
+		}
+	case RuleMemberStatus:
+		if _, ok := gslbsvc.MemberStatus_Status_value[r.Status]; !ok && r.Status != StatusDisabled {
+			return fmt.Errorf("invalid status %q, one of %s, %s expected",
+				r.Status, strings.Join(enumNames(gslbsvc.MemberStatus_ONLINE.Descriptor(), nil), ", "), StatusDisabled)
+		}
+	default:
+		return fmt.Errorf("invalid type %q, one of %s, %s, %s expected", r.Type, RuleNoOnline, RuleMinOnlinePercent, RuleMemberStatus)
+	}
+	return nil
+}
+
+// conditions gives where rule holds in entries status.
+func (r *MonitorRule) conditions(ents []*gslbsvc.GetEntryStatusResponse) []*alertCondition {
+	conds := make([]*alertCondition, 0)
+	for _, ent := range ents {
+		if !strings.HasPrefix(ent.GetFqdn(), r.Prefix) {
+			continue
+		}
+		members := entryMembersStatus(ent)
+		if r.Type == RuleMemberStatus {
+			for _, member := range members {
+				if r.Dc != "" && r.Dc != allDcs && member.GetDc() != r.Dc {
+					continue
+				}
+				if MemberStatusLabel(member) != r.Status {
+					continue
+				}
+				message := fmt.Sprintf("member %s of %s in %s is %s", member.GetIp(), ent.GetFqdn(), member.GetDc(), r.Status)
+				if member.GetFailureReason() != "" {
+					message += ": " + member.GetFailureReason()
+				}
+				conds = append(conds, &alertCondition{rule: r, fqdn: ent.GetFqdn(), dc: member.GetDc(), ip: member.GetIp(), message: message})
+			}
+			continue
+		}
+
+		byDc := map[string][]*gslbsvc.MemberStatus{r.Dc: make([]*gslbsvc.MemberStatus, 0)}
+		if r.Dc == allDcs {
+			byDc = make(map[string][]*gslbsvc.MemberStatus)
+		}
+		for _, member := range members {
+			if IsDisabledByUser(member) {
+				continue
+			}
+			switch r.Dc {
+			case "":
+				byDc[""] = append(byDc[""], member)
+			case allDcs, member.GetDc():
+				byDc[member.GetDc()] = append(byDc[member.GetDc()], member)
+			}
+		}
+		dcs := make([]string, 0, len(byDc))
+		for dc := range byDc {
+			dcs = append(dcs, dc)
+		}
+		sort.Strings(dcs)
+		for _, dc := range dcs {
+			dcMembers := byDc[dc]
+			// nothing to alert on when all members are disabled by user
+			if len(dcMembers) == 0 {
+				continue
+			}
+			online := 0
+			for _, member := range dcMembers {
+				if member.GetStatus() == gslbsvc.MemberStatus_ONLINE {
+					online++
+				}
+			}
+			where := ""
+			if dc != "" {
+				where = " in " + dc
+			}
+			percent := float64(online) * 100 / float64(len(dcMembers))
+			switch {
+			case r.Type == RuleNoOnline && online == 0:
+				conds = append(conds, &alertCondition{rule: r, fqdn: ent.GetFqdn(), dc: dc,
+					message: fmt.Sprintf("%s has no ONLINE members%s (0/%d)", ent.GetFqdn(), where, len(dcMembers))})
+			case r.Type == RuleMinOnlinePercent && percent < r.Percent:
+				conds = append(conds, &alertCondition{rule: r, fqdn: ent.GetFqdn(), dc: dc,
+					message: fmt.Sprintf("%s has %.1f%% ONLINE members%s (%d/%d), less than %g%%",
+						ent.GetFqdn(), percent, where, online, len(dcMembers), r.Percent)})
+			}
+		}
+	}
+	return conds
+}
+
+// Evaluate rules on entries status, events are given for alerts which fire or resolve since previous evaluation.
+func (c *Monitor) Evaluate(ents []*gslbsvc.GetEntryStatusResponse, now time.Time) []*AlertEvent {
+	if c.pending == nil {
+		c.pending = make(map[string]time.Time)
+		c.firing = make(map[string]*AlertEvent)
+	}
+	events := make([]*AlertEvent, 0)
+	holding := make(map[string]bool)
+	for _, rule := range c.config.Rules {
+		for _, cond := range rule.conditions(ents) {
+			key := cond.key()
+			holding[key] = true
+			since, ok := c.pending[key]
+			if !ok {
+				since = now
+				c.pending[key] = now
+			}
+			if _, isFiring := c.firing[key]; isFiring || now.Sub(since) < rule.For {
+				continue
+			}
+			event := &AlertEvent{
+				Time:    now,
+				Rule:    rule.Name,
+				State:   AlertFiring,
+				Fqdn:    cond.fqdn,
+				Dc:      cond.dc,
+				Ip:      cond.ip,
+				Message: cond.message,
+				Since:   since,
+			}
+			c.firing[key] = event
+			events = append(events, event)
+		}
+	}
+
+	resolved := make([]*AlertEvent, 0)
+	for key := range c.pending {
+		if holding[key] {
+			continue
+		}
+		delete(c.pending, key)
+		fired, ok := c.firing[key]
+		if !ok {
+			continue
+		}
+		delete(c.firing, key)
+		event := *fired
+		event.Time = now
+		event.State = AlertResolved
+		resolved = append(resolved, &event)
+	}
+	// resolved events are in rules order like fired ones
+	ranks := make(map[string]int)
+	for i, rule := range c.config.Rules {
+		ranks[rule.Name] = i
+	}
+	sort.Slice(resolved, func(i, j int) bool {
+		if resolved[i].Rule != resolved[j].Rule {
+			return ranks[resolved[i].Rule] < ranks[resolved[j].Rule]
+		}
+		return resolved[i].Message < resolved[j].Message
+	})
+	return append(events, resolved...)
+}
+
+// notify write event on stdout and send it to webhooks and commands, failures are only reported.
+func (c *Monitor) notify(ctx context.Context, event *AlertEvent) {
+	b, err := json.Marshal(event)
+	if err != nil {
+		msg.Error(err.Error())
+		return
+	}
+	fmt.Fprintln(msg.Output(), string(b))
+
+	ctx, cancel := context.WithTimeout(ctx, notifyTimeout)
+	defer cancel()
+	msg.UseStderr()
+	defer msg.UseStdout()
+	for _, webhook := range c.config.Webhooks {
+		err := postEvent(ctx, webhook, b)
+		if err != nil {
+			msg.Error(fmt.Sprintf("Failed to call webhook %s: %s", webhook, err.Error()))
+		}
+	}
+	for _, command := range c.config.Commands {
+		err := runEventCommand(ctx, command, event, b)
+		if err != nil {
+			msg.Error(fmt.Sprintf("Failed to run command %q: %s", command, err.Error()))
+		}
+	}
+}
+
+func postEvent(ctx context.Context, url string, content []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(content))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close() // nolint:errcheck
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// runEventCommand run command with sh, event is given on stdin and in env vars, command output goes to stderr.
+func runEventCommand(ctx context.Context, command string, event *AlertEvent, content []byte) error {
+	cmd := exec.CommandContext(ctx, "sh", "-c", command)
+	cmd.Stdin = bytes.NewReader(content)
+	cmd.Stdout = os.Stderr
+	cmd.Stderr = os.Stderr
+	cmd.Env = append(os.Environ(),
+		"GSLOC_ALERT_RULE="+event.Rule,
+		"GSLOC_ALERT_STATE="+event.State,
+		"GSLOC_ALERT_FQDN="+event.Fqdn,
+		"GSLOC_ALERT_DC="+event.Dc,
+		"GSLOC_ALERT_IP="+event.Ip,
+		"GSLOC_ALERT_MESSAGE="+event.Message,
+	)
+	return cmd.Run()
+}
+
+func init() {
+	desc := "Evaluate alerting rules on entries status and notify when alerts fire or resolve."
+	long := desc + "\nRules file is a yml file with rules, webhooks and commands keys. " +
+		"A rule has a name, a type among no_online, min_online_percent (with percent) or member_status (with status), " +
+		"an optional fqdn prefix, an optional dc (* to check each datacenter) and an optional for duration during which condition must hold, " +
+		"e.g.: {name: failing, type: member_status, status: CHECK_FAILED, for: 5m}."
+	cmd, err := parser.AddCommand(
+		"monitor",
+		desc,
+		long,
+		&monitor)
+	if err != nil {
+		panic(err)
+	}
+	cmd.Aliases = []string{"mon"}
+}
@@ -0,0 +1,127 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	gslbsvc "github.com/orange-cloudfoundry/gsloc-go-sdk/gsloc/services/gslb/v1"
+)
+
+func writeRules(t *testing.T, content string) string {
+	file := filepath.Join(t.TempDir(), "rules.yml")
+	err := os.WriteFile(file, []byte(content), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return file
+}
+
+func TestLoadMonitorConfig(t *testing.T) {
+	config, err := LoadMonitorConfig(writeRules(t, `
+rules:
+- name: failing
+  type: member_status
+  status: CHECK_FAILED
+  for: 5m
+webhooks: [http://localhost/hook]
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if config.Rules[0].For != 5*time.Minute || len(config.Webhooks) != 1 {
+		t.Errorf("Unexpected config %+v", config)
+	}
+
+	for name, content := range map[string]string{
+		"unknown key":    "rules:\n- name: a\n  type: no_online\n  percnt: 10\n",
+		"unknown type":   "rules:\n- name: a\n  type: all_offline\n",
+		"invalid status": "rules:\n- name: a\n  type: member_status\n  status: DOWN\n",
+		"no percent":     "rules:\n- name: a\n  type: min_online_percent\n",
+		"same name":      "rules:\n- name: a\n  type: no_online\n- name: a\n  type: no_online\n",
+		"no rules":       "webhooks: []\n",
+	} {
+		if _, err := LoadMonitorConfig(writeRules(t, content)); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestMonitorEvaluate(t *testing.T) {
+	c := &Monitor{config: &MonitorConfig{Rules: []*MonitorRule{
+		{Name: "no-online", Type: RuleNoOnline},
+		{Name: "dc-degraded", Type: RuleMinOnlinePercent, Dc: allDcs, Percent: 50},
+		{Name: "failing", Type: RuleMemberStatus, Status: "CHECK_FAILED", For: 5 * time.Minute},
+	}}}
+	status := func(dc1, dc2 gslbsvc.MemberStatus_Status) []*gslbsvc.GetEntryStatusResponse {
+		return []*gslbsvc.GetEntryStatusResponse{{
+			Fqdn: "a.example.com.",
+			MembersIpv4: []*gslbsvc.MemberStatus{
+				{Ip: "10.0.0.1", Dc: "dc1", Status: dc1, FailureReason: "timeout"},
+				{Ip: "10.0.0.2", Dc: "dc2", Status: dc2},
+				{Ip: "10.0.0.3", Dc: "dc2", Status: gslbsvc.MemberStatus_CHECK_FAILED, FailureReason: "disabled entry"},
+			},
+		}}
+	}
+	start := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	eventTexts := func(events []*AlertEvent) []string {
+		texts := make([]string, len(events))
+		for i, event := range events {
+			texts[i] = event.State + " " + event.Rule + " " + event.Dc + " " + event.Ip
+		}
+		return texts
+	}
+
+	steps := []struct {
+		after    time.Duration
+		status   []*gslbsvc.GetEntryStatusResponse
+		expected []string
+	}{
+		{0, status(gslbsvc.MemberStatus_ONLINE, gslbsvc.MemberStatus_ONLINE), []string{}},
+		{time.Minute, status(gslbsvc.MemberStatus_CHECK_FAILED, gslbsvc.MemberStatus_ONLINE), []string{"firing dc-degraded dc1 "}},
+		{5 * time.Minute, status(gslbsvc.MemberStatus_CHECK_FAILED, gslbsvc.MemberStatus_OFFLINE), []string{"firing no-online  ", "firing dc-degraded dc2 "}},
+		{6 * time.Minute, status(gslbsvc.MemberStatus_CHECK_FAILED, gslbsvc.MemberStatus_OFFLINE), []string{"firing failing dc1 10.0.0.1"}},
+		{7 * time.Minute, status(gslbsvc.MemberStatus_ONLINE, gslbsvc.MemberStatus_OFFLINE), []string{"resolved no-online  ", "resolved dc-degraded dc1 ", "resolved failing dc1 10.0.0.1"}},
+	}
+	for i, step := range steps {
+		texts := eventTexts(c.Evaluate(step.status, start.Add(step.after)))
+		if strings.Join(texts, "|") != strings.Join(step.expected, "|") {
+			t.Errorf("Step %d: expected events %q, got %q", i, step.expected, texts)
+		}
+	}
+}
+
+func TestMonitorNotify(t *testing.T) {
+	received := make(chan []byte, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		received <- b
+	}))
+	defer server.Close()
+	out := filepath.Join(t.TempDir(), "out")
+
+	c := &Monitor{config: &MonitorConfig{
+		Webhooks: []string{server.URL},
+		Commands: []string{`echo "$GSLOC_ALERT_STATE $GSLOC_ALERT_RULE" > ` + out},
+	}}
+	c.notify(context.Background(), &AlertEvent{Rule: "no-online", State: AlertFiring, Fqdn: "a.example.com."})
+
+	event := &AlertEvent{}
+	if err := json.Unmarshal(<-received, event); err != nil || event.Rule != "no-online" {
+		t.Errorf("Expected event to be posted, got %+v, err %v", event, err)
+	}
+	content, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(content) != "firing no-online\n" {
+		t.Errorf("Expected command to get event in env, got %q", content)
+	}
+}
@@ -0,0 +1,328 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"github.com/ArthurHlt/go-flags"
+	msg "github.com/ArthurHlt/messages"
+	"github.com/orange-cloudfoundry/gsloc-cli/app"
+	gslbsvc "github.com/orange-cloudfoundry/gsloc-go-sdk/gsloc/services/gslb/v1"
+	"google.golang.org/protobuf/proto"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	TrashKindEntry  = "entry"
+	TrashKindMember = "member"
+
+	trashTimeFormat = "20060102T150405Z"
+)
+
+// TrashItem is a deleted entry or member saved locally, it can be restored with restore command.
+type TrashItem struct {
+	Id        string    `json:"id"`
+	Kind      string    `json:"kind"`
+	Name      string    `json:"name"`
+	DeletedAt time.Time `json:"deleted_at"`
+	Path      string    `json:"-"`
+}
+
+type TrashId struct {
+	content string
+}
+
+func (n *TrashId) String() string {
+	return n.content
+}
+
+func (n *TrashId) Complete(match string) []flags.Completion {
+	opts.ConfigPath = defaultConfigPath
+	if os.Getenv("GSLOC_CONFIG_PATH") != "" {
+		opts.ConfigPath = os.Getenv("GSLOC_CONFIG_PATH")
+	}
+	opts.Target = os.Getenv("GSLOC_TARGET")
+
+	dir, err := trashDir()
+	if err != nil {
+		return []flags.Completion{
+			{Item: "Error: " + err.Error()},
+		}
+	}
+	items, err := ListTrash(dir)
+	if err != nil {
+		return []flags.Completion{
+			{Item: "Error: " + err.Error()},
+		}
+	}
+	completions := make([]flags.Completion, 0)
+	for _, item := range items {
+		if strings.HasPrefix(item.Id, match) {
+			completions = append(completions, flags.Completion{Item: item.Id})
+		}
+	}
+	return completions
+}
+
+func (n *TrashId) UnmarshalFlag(value string) error {
+	n.content = value
+	return nil
+}
+
+type Restore struct {
+	Id *TrashId `positional-args:"true" positional-arg-name:"'id'"`
+
+	Force bool `long:"force" description:"Restore without confirmation"`
+
+	OutputFormat
+
+	client gslbsvc.GSLBClient
+}
+
+var restore Restore
+
+func (c *Restore) SetClient(client gslbsvc.GSLBClient) {
+	c.client = client
+}
+
+func (c *Restore) Execute([]string) error {
+	dir, err := trashDir()
+	if err != nil {
+		return err
+	}
+	items, err := ListTrash(dir)
+	if err != nil {
+		return err
+	}
+	if c.Id.String() == "" {
+		return c.list(items)
+	}
+	for _, item := range items {
+		if item.Id == c.Id.String() {
+			return c.restore(item)
+		}
+	}
+	return fmt.Errorf("no deleted object with id %s in trash %s", c.Id.String(), dir)
+}
+
+func (c *Restore) list(items []*TrashItem) error {
+	names := make([]string, len(items))
+	for i, item := range items {
+		names[i] = item.Id
+	}
+	return c.Print(items, names, func(bool) error {
+		if len(items) == 0 {
+			msg.Info("Trash is empty.")
+			return nil
+		}
+		table := MakeTableWriter([]string{"Id", "Kind", "Name", "Deleted At"})
+		for _, item := range items {
+			table.Append([]string{item.Id, item.Kind, item.Name, item.DeletedAt.Local().Format(time.RFC3339)})
+		}
+		table.Render()
+		return nil
+	})
+}
+
+func (c *Restore) restore(item *TrashItem) error {
+	content, err := os.ReadFile(item.Path)
+	if err != nil {
+		return err
+	}
+	var restored bool
+	switch item.Kind {
+	case TrashKindEntry:
+		restored, err = c.restoreEntry(content, item.Path)
+	case TrashKindMember:
+		restored, err = c.restoreMember(content, item.Path)
+	default:
+		return fmt.Errorf("unknown kind %s for %s", item.Kind, item.Id)
+	}
+	if err != nil || !restored {
+		return err
+	}
+	return os.Remove(item.Path)
+}
+
+func (c *Restore) restoreEntry(content []byte, name string) (bool, error) {
+	setEntryReq, loaded, err := BytesToProto[*gslbsvc.SetEntryRequest](content, name)
+	if err != nil {
+		return false, err
+	}
+	if !loaded {
+		return false, fmt.Errorf("file %s is empty", name)
+	}
+	var current *gslbsvc.SetEntryRequest
+	entResp, err := c.client.GetEntry(context.Background(), &gslbsvc.GetEntryRequest{
+		Fqdn: setEntryReq.GetEntry().GetFqdn(),
+	})
+	if err != nil && !strings.Contains(err.Error(), "not found") {
+		return false, err
+	}
+	if err == nil {
+		current = &gslbsvc.SetEntryRequest{
+			Entry:       entResp.GetEntry(),
+			Healthcheck: entResp.GetHealthcheck(),
+		}
+	}
+	confirm, err := DiffAndConfirm(current, setEntryReq, c.Force)
+	if err != nil {
+		return false, err
+	}
+	if !confirm {
+		return false, nil
+	}
+	_, err = c.client.SetEntry(context.Background(), setEntryReq)
+	if err != nil {
+		return false, err
+	}
+	msg.Successf("Entry %s restored successfully.", msg.Cyan(setEntryReq.GetEntry().GetFqdn()))
+	return true, nil
+}
+
+func (c *Restore) restoreMember(content []byte, name string) (bool, error) {
+	setMemberReq, loaded, err := BytesToProto[*gslbsvc.SetMemberRequest](content, name)
+	if err != nil {
+		return false, err
+	}
+	if !loaded {
+		return false, fmt.Errorf("file %s is empty", name)
+	}
+	var current *gslbsvc.SetMemberRequest
+	resp, err := c.client.GetMember(context.Background(), &gslbsvc.GetMemberRequest{
+		Fqdn: setMemberReq.GetFqdn(),
+		Ip:   setMemberReq.GetMember().GetIp(),
+	})
+	if err != nil && !strings.Contains(err.Error(), "not found") {
+		return false, err
+	}
+	if err == nil {
+		current = &gslbsvc.SetMemberRequest{
+			Fqdn:   setMemberReq.GetFqdn(),
+			Member: resp.GetMember(),
+		}
+	}
+	confirm, err := DiffAndConfirm(current, setMemberReq, c.Force)
+	if err != nil {
+		return false, err
+	}
+	if !confirm {
+		return false, nil
+	}
+	_, err = c.client.SetMember(context.Background(), setMemberReq)
+	if err != nil {
+		return false, err
+	}
+	msg.Successf("Member %s on %s restored successfully.", msg.Cyan(setMemberReq.GetMember().GetIp()), msg.Cyan(setMemberReq.GetFqdn()))
+	return true, nil
+}
+
+// trashDir gives trash directory of current target.
+func trashDir() (string, error) {
+	target, err := app.GetTarget(ExpandConfigPath(), opts.Target)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(filepath.Dir(ExpandConfigPath()), "trash", target.Name), nil
+}
+
+// SaveToTrash write a deleted object as a manifest in trash directory,
+// member name is in the form <fqdn>_<ip>.
+// Id starts with an operation id so objects deleted in the same second do not override each other.
+func SaveToTrash(dir, kind, name string, pMsg proto.Message, deletedAt time.Time) (*TrashItem, error) {
+	err := os.MkdirAll(dir, 0700)
+	if err != nil {
+		return nil, err
+	}
+	content, err := ProtoToManifest(pMsg)
+	if err != nil {
+		return nil, err
+	}
+	name = strings.NewReplacer(":", "-", "/", "-").Replace(strings.TrimSuffix(name, "."))
+	item := &TrashItem{
+		Id:        fmt.Sprintf("%s_%s_%s", app.NewOperationId(deletedAt), kind, name),
+		Kind:      kind,
+		Name:      name,
+		DeletedAt: deletedAt.UTC().Truncate(time.Second),
+	}
+	item.Path = filepath.Join(dir, item.Id+".yml")
+	err = os.WriteFile(item.Path, content, 0600)
+	if err != nil {
+		return nil, err
+	}
+	return item, nil
+}
+
+// ListTrash gives objects in trash directory, most recently deleted first.
+func ListTrash(dir string) ([]*TrashItem, error) {
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []*TrashItem{}, nil
+		}
+		return nil, err
+	}
+	items := make([]*TrashItem, 0)
+	for _, file := range files {
+		if file.IsDir() || filepath.Ext(file.Name()) != ".yml" {
+			continue
+		}
+		id := strings.TrimSuffix(file.Name(), ".yml")
+		parts := strings.SplitN(id, "_", 3)
+		if len(parts) != 3 {
+			continue
+		}
+		// operation id is the deletion time followed by a random suffix
+		deletedTime, _, _ := strings.Cut(parts[0], "-")
+		deletedAt, err := time.Parse(trashTimeFormat, deletedTime)
+		if err != nil {
+			continue
+		}
+		items = append(items, &TrashItem{
+			Id:        id,
+			Kind:      parts[1],
+			Name:      parts[2],
+			DeletedAt: deletedAt,
+			Path:      filepath.Join(dir, file.Name()),
+		})
+	}
+	sort.SliceStable(items, func(i, j int) bool {
+		return items[i].DeletedAt.After(items[j].DeletedAt)
+	})
+	return items, nil
+}
+
+// deleteWithTrash save object in trash of current target before deleting it,
+// saved object is removed from trash if deletion fails.
+func deleteWithTrash(kind, name string, pMsg proto.Message, deleteFunc func() error) (*TrashItem, error) {
+	dir, err := trashDir()
+	if err != nil {
+		return nil, err
+	}
+	item, err := SaveToTrash(dir, kind, name, pMsg, time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("failed to save %s %s in trash: %w", kind, name, err)
+	}
+	err = deleteFunc()
+	if err != nil {
+		os.Remove(item.Path) // nolint:errcheck
+		return nil, err
+	}
+	return item, nil
+}
+
+func init() {
+	desc := "List deleted entries and members or restore one of them from local trash."
+	cmd, err := parser.AddCommand(
+		"restore",
+		desc,
+		desc,
+		&restore)
+	if err != nil {
+		panic(err)
+	}
+	cmd.Aliases = []string{"undelete"}
+}
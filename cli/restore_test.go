@@ -0,0 +1,75 @@
+package cli
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/orange-cloudfoundry/gsloc-go-sdk/gsloc/api/config/entries/v1"
+	gslbsvc "github.com/orange-cloudfoundry/gsloc-go-sdk/gsloc/services/gslb/v1"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestTrash(t *testing.T) {
+	dir := t.TempDir()
+	deletedAt := time.Date(2023, 1, 1, 10, 0, 0, 0, time.UTC)
+	member := &gslbsvc.SetMemberRequest{
+		Fqdn:   "a.example.com.",
+		Member: &entries.Member{Ip: "2001:db8::1", Dc: "dc1", Ratio: 10},
+	}
+	entry := &gslbsvc.SetEntryRequest{
+		Entry: &entries.Entry{Fqdn: "a.example.com.", Ttl: 30},
+	}
+
+	memberItem, err := SaveToTrash(dir, TrashKindMember, "a.example.com_2001:db8::1", member, deletedAt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(memberItem.Id, "20230101T100000Z-") || !strings.HasSuffix(memberItem.Id, "_member_a.example.com_2001-db8--1") {
+		t.Errorf("Unexpected id %s", memberItem.Id)
+	}
+	_, err = SaveToTrash(dir, TrashKindEntry, "a.example.com.", entry, deletedAt.Add(time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// deleted again in the same second, e.g. after a restore
+	_, err = SaveToTrash(dir, TrashKindEntry, "a.example.com.", entry, deletedAt.Add(time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	items, err := ListTrash(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 3 {
+		t.Fatalf("Expected 3 items but got %d", len(items))
+	}
+	if items[0].Id == items[1].Id {
+		t.Errorf("Expected entries deleted in the same second to have different ids, got %s", items[0].Id)
+	}
+	if items[0].Kind != TrashKindEntry || items[0].Name != "a.example.com" {
+		t.Errorf("Expected most recent entry first but got %s %s", items[0].Kind, items[0].Name)
+	}
+	if items[2].Id != memberItem.Id || !items[2].DeletedAt.Equal(deletedAt) {
+		t.Errorf("Unexpected member item %+v", items[2])
+	}
+
+	content, err := os.ReadFile(items[2].Path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	loadedMember, _, err := BytesToProto[*gslbsvc.SetMemberRequest](content, items[2].Path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !proto.Equal(loadedMember, member) {
+		t.Errorf("Expected %v but got %v", member, loadedMember)
+	}
+
+	items, err = ListTrash(dir + "/unknown")
+	if err != nil || len(items) != 0 {
+		t.Errorf("Expected empty trash when directory does not exist, got %v, %v", items, err)
+	}
+}
@@ -0,0 +1,380 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/ArthurHlt/go-flags"
+	msg "github.com/ArthurHlt/messages"
+	"github.com/orange-cloudfoundry/gsloc-cli/app"
+	gslbsvc "github.com/orange-cloudfoundry/gsloc-go-sdk/gsloc/services/gslb/v1"
+	"google.golang.org/protobuf/proto"
+	"os"
+	"strings"
+	"time"
+)
+
+const rollbackKindHealthcheck = "healthcheck"
+
+// RollbackStep is a change to make for going back to the state before an operation,
+// Previous is nil when object did not exist before and must be deleted.
+type RollbackStep struct {
+	Kind     string
+	Fqdn     string
+	Ip       string
+	Previous proto.Message
+}
+
+func (s *RollbackStep) String() string {
+	if s.Ip != "" {
+		return fmt.Sprintf("%s %s on %s", s.Kind, s.Ip, s.Fqdn)
+	}
+	return fmt.Sprintf("%s %s", s.Kind, s.Fqdn)
+}
+
+const rollbackCommandName = "rollback"
+
+type OperationId struct {
+	content string
+}
+
+func (n *OperationId) String() string {
+	return n.content
+}
+
+func (n *OperationId) Complete(match string) []flags.Completion {
+	opts.ConfigPath = defaultConfigPath
+	if os.Getenv("GSLOC_CONFIG_PATH") != "" {
+		opts.ConfigPath = os.Getenv("GSLOC_CONFIG_PATH")
+	}
+	records, err := app.NewJournal(app.JournalPath(ExpandConfigPath())).Records()
+	if err != nil {
+		return []flags.Completion{
+			{Item: "Error: " + err.Error()},
+		}
+	}
+	completions := make([]flags.Completion, 0)
+	seen := make(map[string]bool)
+	for i := len(records) - 1; i >= 0; i-- {
+		operation := records[i].Operation
+		if seen[operation] || !strings.HasPrefix(operation, match) {
+			continue
+		}
+		seen[operation] = true
+		completions = append(completions, flags.Completion{
+			Item:        operation,
+			Description: records[i].Command,
+		})
+	}
+	return completions
+}
+
+func (n *OperationId) UnmarshalFlag(value string) error {
+	n.content = value
+	return nil
+}
+
+type Rollback struct {
+	Operation *OperationId `positional-args:"true" positional-arg-name:"'op-id'"`
+
+	Force bool `long:"force" description:"Rollback without confirmation"`
+
+	client gslbsvc.GSLBClient
+}
+
+var rollback Rollback
+
+func (c *Rollback) SetClient(client gslbsvc.GSLBClient) {
+	c.client = client
+}
+
+func (c *Rollback) Execute([]string) error {
+	target, err := app.GetTarget(ExpandConfigPath(), opts.Target)
+	if err != nil {
+		return err
+	}
+	records, err := app.NewJournal(app.JournalPath(ExpandConfigPath())).Records()
+	if err != nil {
+		return err
+	}
+	records = OperationRecords(records, c.Operation.String(), target.Name)
+	if len(records) == 0 {
+		if c.Operation.String() == "" {
+			return fmt.Errorf("no operation found in journal for target %s", target.Name)
+		}
+		return fmt.Errorf("operation %s not found in journal", c.Operation)
+	}
+	if records[0].Target != target.Name {
+		return fmt.Errorf("operation %s was made on target %s, use --target %s", records[0].Operation, records[0].Target, records[0].Target)
+	}
+	steps, err := RollbackSteps(records)
+	if err != nil {
+		return err
+	}
+	msg.Infof("Rollback operation %s made by %s with command %s at %s.",
+		msg.Cyan(records[0].Operation), records[0].Username, msg.Cyan(records[0].Command),
+		records[0].Time.Local().Format(time.RFC3339))
+	if len(steps) == 0 {
+		msg.Info("Nothing to rollback.")
+		return nil
+	}
+
+	nbFailed := 0
+	for _, step := range steps {
+		msg.Printf("━━━━━\n")
+		msg.Infof("Rollback %s", msg.Cyan(step))
+		err = c.rollbackStep(step)
+		if err != nil {
+			nbFailed++
+			msg.Error(fmt.Sprintf("Failed to rollback %s: %s", step, err.Error()))
+		}
+	}
+	if nbFailed > 0 {
+		return fmt.Errorf("%d on %d rollbacks failed", nbFailed, len(steps))
+	}
+	return nil
+}
+
+func (c *Rollback) rollbackStep(step *RollbackStep) error {
+	ctx := context.Background()
+	var current proto.Message
+	var set, remove func() error
+	switch step.Kind {
+	case TrashKindEntry:
+		entResp, err := c.client.GetEntry(ctx, &gslbsvc.GetEntryRequest{Fqdn: step.Fqdn})
+		if err != nil && !isNotFound(err) {
+			return err
+		}
+		if err == nil {
+			current = &gslbsvc.SetEntryRequest{
+				Entry:       entResp.GetEntry(),
+				Healthcheck: entResp.GetHealthcheck(),
+			}
+		}
+		set = func() error {
+			_, err := c.client.SetEntry(ctx, step.Previous.(*gslbsvc.SetEntryRequest))
+			return err
+		}
+		remove = func() error {
+			_, err := c.client.DeleteEntry(ctx, &gslbsvc.DeleteEntryRequest{Fqdn: step.Fqdn})
+			return err
+		}
+	case rollbackKindHealthcheck:
+		if step.Previous == nil {
+			msg.Warning(fmt.Sprintf("Healthcheck of %s did not exist before and can't be removed, skipping.", step.Fqdn))
+			return nil
+		}
+		hcResp, err := c.client.GetHealthCheck(ctx, &gslbsvc.GetHealthCheckRequest{Fqdn: step.Fqdn})
+		if err != nil && !isNotFound(err) {
+			return err
+		}
+		if err == nil {
+			current = &gslbsvc.SetHealthCheckRequest{
+				Fqdn:        step.Fqdn,
+				Healthcheck: hcResp.GetHealthcheck(),
+			}
+		}
+		set = func() error {
+			_, err := c.client.SetHealthCheck(ctx, step.Previous.(*gslbsvc.SetHealthCheckRequest))
+			return err
+		}
+	case TrashKindMember:
+		memberResp, err := c.client.GetMember(ctx, &gslbsvc.GetMemberRequest{Fqdn: step.Fqdn, Ip: step.Ip})
+		if err != nil && !isNotFound(err) {
+			return err
+		}
+		if err == nil {
+			current = &gslbsvc.SetMemberRequest{
+				Fqdn:   step.Fqdn,
+				Member: memberResp.GetMember(),
+			}
+		}
+		set = func() error {
+			_, err := c.client.SetMember(ctx, step.Previous.(*gslbsvc.SetMemberRequest))
+			return err
+		}
+		remove = func() error {
+			_, err := c.client.DeleteMember(ctx, &gslbsvc.DeleteMemberRequest{Fqdn: step.Fqdn, Ip: step.Ip})
+			return err
+		}
+	default:
+		return fmt.Errorf("unknown kind %s", step.Kind)
+	}
+
+	if (current == nil && step.Previous == nil) || (current != nil && step.Previous != nil && proto.Equal(current, step.Previous)) {
+		msg.Info("Already in previous state.")
+		return nil
+	}
+	confirm, err := DiffAndConfirm(current, step.Previous, c.Force)
+	if err != nil || !confirm {
+		return err
+	}
+	if step.Previous == nil {
+		err = remove()
+	} else {
+		err = set()
+	}
+	if err != nil {
+		return err
+	}
+	msg.Successf("%s rolled back successfully.", strings.ToUpper(step.Kind[:1])+step.Kind[1:])
+	return nil
+}
+
+// OperationRecords gives records of an operation, when operation is empty
+// the last operation on target which succeeded to change something and was not rolled back is used.
+// Each rollback operation is considered to undo the operation before it, so consecutive rollbacks go back in history.
+func OperationRecords(records []*app.AuditRecord, operation, target string) []*app.AuditRecord {
+	if operation == "" {
+		seen := make(map[string]bool)
+		rolledBack := 0
+		for i := len(records) - 1; i >= 0; i-- {
+			record := records[i]
+			if record.Target != target || record.Result != app.AuditResultSuccess || seen[record.Operation] {
+				continue
+			}
+			seen[record.Operation] = true
+			if record.Command == rollbackCommandName {
+				rolledBack++
+				continue
+			}
+			if rolledBack > 0 {
+				rolledBack--
+				continue
+			}
+			operation = record.Operation
+			break
+		}
+	}
+	opRecords := make([]*app.AuditRecord, 0)
+	if operation == "" {
+		return opRecords
+	}
+	for _, record := range records {
+		if record.Operation == operation {
+			opRecords = append(opRecords, record)
+		}
+	}
+	return opRecords
+}
+
+// RollbackSteps gives changes to make for going back to the state before records, failed calls are ignored.
+// When an object has been changed multiple times only its first previous state is kept,
+// steps are in reverse order of the changes.
+func RollbackSteps(records []*app.AuditRecord) ([]*RollbackStep, error) {
+	steps := make([]*RollbackStep, 0)
+	seen := make(map[string]bool)
+	for _, record := range records {
+		if record.Result != app.AuditResultSuccess {
+			continue
+		}
+		if record.PriorError != "" {
+			return nil, fmt.Errorf("can't rollback %s on %s, state before could not be retrieved: %s",
+				record.Method, strings.Join(record.Objects, ", "), record.PriorError)
+		}
+		recordSteps, err := recordRollbackSteps(record)
+		if err != nil {
+			return nil, err
+		}
+		for _, step := range recordSteps {
+			key := step.Kind + " " + step.Fqdn + " " + step.Ip
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			steps = append(steps, step)
+		}
+	}
+	for i, j := 0, len(steps)-1; i < j; i, j = i+1, j-1 {
+		steps[i], steps[j] = steps[j], steps[i]
+	}
+	return steps, nil
+}
+
+func recordRollbackSteps(record *app.AuditRecord) ([]*RollbackStep, error) {
+	var fqdn, ip string
+	if len(record.Objects) > 0 {
+		fqdn, ip, _ = strings.Cut(record.Objects[0], " ")
+	}
+	switch record.Method {
+	case "SetEntry", "DeleteEntry":
+		previous, err := rawJsonToProto[*gslbsvc.SetEntryRequest](record.Prior)
+		if err != nil {
+			return nil, err
+		}
+		if previous == nil && record.Method == "DeleteEntry" {
+			return nil, nil
+		}
+		return []*RollbackStep{{Kind: TrashKindEntry, Fqdn: fqdn, Previous: previous}}, nil
+	case "SetHealthCheck":
+		previous, err := rawJsonToProto[*gslbsvc.SetHealthCheckRequest](record.Prior)
+		if err != nil {
+			return nil, err
+		}
+		return []*RollbackStep{{Kind: rollbackKindHealthcheck, Fqdn: fqdn, Previous: previous}}, nil
+	case "SetMember", "DeleteMember":
+		previous, err := rawJsonToProto[*gslbsvc.SetMemberRequest](record.Prior)
+		if err != nil {
+			return nil, err
+		}
+		if previous == nil && record.Method == "DeleteMember" {
+			return nil, nil
+		}
+		return []*RollbackStep{{Kind: TrashKindMember, Fqdn: fqdn, Ip: ip, Previous: previous}}, nil
+	case "SetMembersStatus":
+		priors := make([]json.RawMessage, 0)
+		if len(record.Prior) > 0 {
+			err := json.Unmarshal(record.Prior, &priors)
+			if err != nil {
+				return nil, fmt.Errorf("invalid previous state for operation %s: %w", record.Operation, err)
+			}
+		}
+		steps := make([]*RollbackStep, 0, len(priors))
+		for _, prior := range priors {
+			previous, err := rawJsonToProto[*gslbsvc.SetMemberRequest](prior)
+			if err != nil {
+				return nil, err
+			}
+			if previous == nil {
+				continue
+			}
+			previousMember := previous.(*gslbsvc.SetMemberRequest)
+			steps = append(steps, &RollbackStep{
+				Kind:     TrashKindMember,
+				Fqdn:     previousMember.GetFqdn(),
+				Ip:       previousMember.GetMember().GetIp(),
+				Previous: previous,
+			})
+		}
+		return steps, nil
+	}
+	return nil, fmt.Errorf("unknown method %s in operation %s", record.Method, record.Operation)
+}
+
+// rawJsonToProto gives a nil interface when content is empty to let callers know object did not exist.
+func rawJsonToProto[T proto.Message](content json.RawMessage) (proto.Message, error) {
+	if len(content) == 0 {
+		return nil, nil
+	}
+	pMsg, loaded, err := BytesToProto[T](content, "prior.json")
+	if err != nil {
+		return nil, err
+	}
+	if !loaded {
+		return nil, nil
+	}
+	return pMsg, nil
+}
+
+func init() {
+	desc := "Rollback an operation recorded in journal, last operation on target is used when no id is given."
+	cmd, err := parser.AddCommand(
+		rollbackCommandName,
+		desc,
+		desc,
+		&rollback)
+	if err != nil {
+		panic(err)
+	}
+	cmd.Aliases = []string{"undo"}
+}
@@ -0,0 +1,159 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/orange-cloudfoundry/gsloc-cli/app"
+	"github.com/orange-cloudfoundry/gsloc-go-sdk/gsloc/api/config/entries/v1"
+	gslbsvc "github.com/orange-cloudfoundry/gsloc-go-sdk/gsloc/services/gslb/v1"
+)
+
+func TestRollback(t *testing.T) {
+	journal := app.NewJournal(filepath.Join(t.TempDir(), "journal.jsonl"))
+	fake := &fakeClient{
+		entries: map[string]*gslbsvc.GetEntryResponse{
+			"a.example.com.": {Entry: &entries.Entry{
+				Fqdn: "a.example.com.",
+				Ttl:  30,
+				MembersIpv4: []*entries.Member{
+					{Ip: "10.0.0.1", Dc: "dc1", Ratio: 10},
+					{Ip: "10.0.0.2", Dc: "dc2", Ratio: 10},
+				},
+			}},
+		},
+	}
+	ctx := context.Background()
+
+	// first operation is kept to check that only last one is rolled back
+	first := NewAuditClient(fake, journal, app.AuditRecord{Operation: "op1", Target: "prod"})
+	_, err := first.SetMember(ctx, &gslbsvc.SetMemberRequest{
+		Fqdn:   "a.example.com.",
+		Member: &entries.Member{Ip: "10.0.0.1", Dc: "dc1", Ratio: 20},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	second := NewAuditClient(fake, journal, app.AuditRecord{Operation: "op2", Target: "prod"})
+	_, err = second.SetEntry(ctx, &gslbsvc.SetEntryRequest{Entry: &entries.Entry{Fqdn: "b.example.com."}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = second.DeleteMember(ctx, &gslbsvc.DeleteMemberRequest{Fqdn: "a.example.com.", Ip: "10.0.0.2"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = second.DeleteMember(ctx, &gslbsvc.DeleteMemberRequest{Fqdn: "a.example.com.", Ip: "10.0.0.9"})
+	if err == nil {
+		t.Fatal("Expected error when deleting unknown member")
+	}
+
+	records, err := journal.Records()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ops := OperationRecords(records, "", "staging"); len(ops) != 0 {
+		t.Errorf("Expected no operation on another target but got %d records", len(ops))
+	}
+	opRecords := OperationRecords(records, "", "prod")
+	if len(opRecords) != 3 || opRecords[0].Operation != "op2" {
+		t.Fatalf("Expected 3 records of last operation but got %v", opRecords)
+	}
+
+	steps, err := RollbackSteps(opRecords)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(steps) != 2 {
+		t.Fatalf("Expected 2 steps, failed call must be ignored, but got %v", steps)
+	}
+	if steps[0].String() != "member 10.0.0.2 on a.example.com." || steps[0].Previous == nil {
+		t.Errorf("Expected deleted member to be re-created first but got %s", steps[0])
+	}
+	if steps[1].String() != "entry b.example.com." || steps[1].Previous != nil {
+		t.Errorf("Expected created entry to be deleted but got %s", steps[1])
+	}
+
+	rollbackCmd := &Rollback{Force: true, client: fake}
+	for _, step := range steps {
+		err = rollbackCmd.rollbackStep(step)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, ok := fake.entries["b.example.com."]; ok {
+		t.Errorf("Expected created entry to be deleted")
+	}
+	members := fake.entries["a.example.com."].GetEntry().GetMembersIpv4()
+	if len(members) != 2 || members[0].GetRatio() != 20 || members[1].GetIp() != "10.0.0.2" {
+		t.Errorf("Expected deleted member to be back and change of first operation to be kept but got %v", members)
+	}
+
+	opRecords[0].PriorError = "unavailable"
+	if _, err = RollbackSteps(opRecords); err == nil {
+		t.Errorf("Expected error when previous state is unknown")
+	}
+}
+
+func TestRollbackTwice(t *testing.T) {
+	journal := app.NewJournal(filepath.Join(t.TempDir(), "journal.jsonl"))
+	fake := &fakeClient{
+		entries: map[string]*gslbsvc.GetEntryResponse{
+			"a.example.com.": {Entry: &entries.Entry{
+				Fqdn:        "a.example.com.",
+				MembersIpv4: []*entries.Member{{Ip: "10.0.0.1", Dc: "dc1", Ratio: 10}},
+			}},
+		},
+	}
+	for i, ratio := range []uint32{20, 30} {
+		client := NewAuditClient(fake, journal, app.AuditRecord{Operation: fmt.Sprintf("op%d", i+1), Target: "prod", Command: "set-member"})
+		_, err := client.SetMember(context.Background(), &gslbsvc.SetMemberRequest{
+			Fqdn:   "a.example.com.",
+			Member: &entries.Member{Ip: "10.0.0.1", Dc: "dc1", Ratio: ratio},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// each rollback must undo the operation before the last rolled back one
+	for i, expected := range []struct {
+		operation string
+		ratio     uint32
+	}{{"op2", 20}, {"op1", 10}} {
+		records, err := journal.Records()
+		if err != nil {
+			t.Fatal(err)
+		}
+		opRecords := OperationRecords(records, "", "prod")
+		if len(opRecords) == 0 || opRecords[0].Operation != expected.operation {
+			t.Fatalf("Expected rollback %d to choose %s but got %v", i+1, expected.operation, opRecords)
+		}
+		steps, err := RollbackSteps(opRecords)
+		if err != nil {
+			t.Fatal(err)
+		}
+		client := NewAuditClient(fake, journal, app.AuditRecord{Operation: fmt.Sprintf("rollback%d", i+1), Target: "prod", Command: "rollback"})
+		rollbackCmd := &Rollback{Force: true, client: client}
+		for _, step := range steps {
+			err = rollbackCmd.rollbackStep(step)
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+		if ratio := fake.entries["a.example.com."].GetEntry().GetMembersIpv4()[0].GetRatio(); ratio != expected.ratio {
+			t.Errorf("Expected ratio %d after rollback %d but got %d", expected.ratio, i+1, ratio)
+		}
+	}
+
+	records, err := journal.Records()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if opRecords := OperationRecords(records, "", "prod"); len(opRecords) != 0 {
+		t.Errorf("Expected nothing left to rollback but got %v", opRecords)
+	}
+}
@@ -0,0 +1,400 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/ArthurHlt/go-flags"
+	pgv "github.com/envoyproxy/protoc-gen-validate/validate"
+	hcconf "github.com/orange-cloudfoundry/gsloc-go-sdk/gsloc/api/config/healthchecks/v1"
+	gslbsvc "github.com/orange-cloudfoundry/gsloc-go-sdk/gsloc/services/gslb/v1"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"os"
+	"sort"
+	"strings"
+)
+
+const jsonSchemaDraft = "http://json-schema.org/draft-07/schema#"
+
+// schemaKinds are messages consumed by files given to commands, indexed by kind name.
+var schemaKinds = map[string]proto.Message{
+	"entry":       &gslbsvc.SetEntryRequest{},
+	"healthcheck": &hcconf.HealthCheck{},
+	"member":      &gslbsvc.SetMemberRequest{},
+}
+
+// numericRulesSchemaKeys map fields of numeric validate rules to their json schema keyword.
+var numericRulesSchemaKeys = map[protoreflect.Name]string{
+	"const": "const",
+	"lt":    "exclusiveMaximum",
+	"lte":   "maximum",
+	"gt":    "exclusiveMinimum",
+	"gte":   "minimum",
+	"in":    "enum",
+}
+
+type SchemaKind struct {
+	content string
+}
+
+func (n *SchemaKind) String() string {
+	if n == nil || n.content == "" {
+		return "entry"
+	}
+	return n.content
+}
+
+func (n *SchemaKind) Complete(match string) []flags.Completion {
+	completions := make([]flags.Completion, 0)
+	for _, kind := range schemaKindNames() {
+		if strings.HasPrefix(kind, match) {
+			completions = append(completions, flags.Completion{Item: kind})
+		}
+	}
+	return completions
+}
+
+func (n *SchemaKind) UnmarshalFlag(value string) error {
+	if _, ok := schemaKinds[value]; !ok {
+		return fmt.Errorf("unknown kind %s, must be one of %s", value, strings.Join(schemaKindNames(), ", "))
+	}
+	n.content = value
+	return nil
+}
+
+func schemaKindNames() []string {
+	names := make([]string, 0, len(schemaKinds))
+	for name := range schemaKinds {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+type Schema struct {
+	Kind *SchemaKind `positional-args:"true" positional-arg-name:"'entry|healthcheck|member'"`
+
+	OutputFile flags.Filename `long:"output-file" description:"Write schema in this file instead of stdout"`
+}
+
+var schema Schema
+
+func (c *Schema) Execute([]string) error {
+	b, err := json.MarshalIndent(JsonSchema(schemaKinds[c.Kind.String()]), "", "  ")
+	if err != nil {
+		return err
+	}
+	if c.OutputFile == "" {
+		fmt.Println(string(b))
+		return nil
+	}
+	return os.WriteFile(string(c.OutputFile), append(b, '\n'), 0644)
+}
+
+// JsonSchema gives a draft-07 json schema of a message as written in manifests (proto names are described),
+// messages are put in definitions to support recursive messages.
+func JsonSchema(pMsg proto.Message) map[string]any {
+	g := &schemaGenerator{
+		definitions: make(map[string]any),
+	}
+	ref := g.messageSchema(pMsg.ProtoReflect().Descriptor())
+	return map[string]any{
+		"$schema":     jsonSchemaDraft,
+		"title":       string(pMsg.ProtoReflect().Descriptor().FullName()),
+		"$ref":        ref["$ref"],
+		"definitions": g.definitions,
+	}
+}
+
+type schemaGenerator struct {
+	definitions map[string]any
+}
+
+// messageSchema gives schema of well known types as protojson write them, a reference to definitions otherwise.
+func (g *schemaGenerator) messageSchema(md protoreflect.MessageDescriptor) map[string]any {
+	switch md.FullName() {
+	case "google.protobuf.Duration":
+		return map[string]any{"type": "string", "pattern": `^-?[0-9]+(\.[0-9]{1,9})?s$`}
+	case "google.protobuf.Timestamp":
+		return map[string]any{"type": "string", "format": "date-time"}
+	case "google.protobuf.FieldMask":
+		return map[string]any{"type": "string"}
+	case "google.protobuf.Struct", "google.protobuf.Empty":
+		return map[string]any{"type": "object"}
+	case "google.protobuf.ListValue":
+		return map[string]any{"type": "array"}
+	case "google.protobuf.Value":
+		return map[string]any{}
+	case "google.protobuf.Any":
+		return map[string]any{
+			"type":       "object",
+			"properties": map[string]any{"@type": map[string]any{"type": "string"}},
+			"required":   []string{"@type"},
+		}
+	}
+	if md.ParentFile().Package() == "google.protobuf" && strings.HasSuffix(string(md.Name()), "Value") {
+		return g.kindSchema(md.Fields().ByName("value"))
+	}
+
+	name := string(md.FullName())
+	ref := map[string]any{"$ref": "#/definitions/" + name}
+	if _, ok := g.definitions[name]; ok {
+		return ref
+	}
+	// additional properties are not refused as manifests also accept json names (camelCase) of fields
+	def := map[string]any{
+		"title": name,
+		"type":  "object",
+	}
+	// registered before walking fields to stop on recursive messages
+	g.definitions[name] = def
+
+	properties := make(map[string]any)
+	required := make([]string, 0)
+	for i := 0; i < md.Fields().Len(); i++ {
+		fd := md.Fields().Get(i)
+		var isRequired bool
+		properties[string(fd.Name())], isRequired = g.fieldSchema(fd)
+		if isRequired {
+			required = append(required, string(fd.Name()))
+		}
+	}
+	def["properties"] = properties
+	if len(required) > 0 {
+		def["required"] = required
+	}
+
+	oneOfs := make([]any, 0)
+	for i := 0; i < md.Oneofs().Len(); i++ {
+		od := md.Oneofs().Get(i)
+		if od.IsSynthetic() {
+			continue
+		}
+		oneOfs = append(oneOfs, oneofSchema(od))
+	}
+	switch len(oneOfs) {
+	case 0:
+	case 1:
+		def["oneOf"] = oneOfs[0].(map[string]any)["oneOf"]
+	default:
+		def["allOf"] = oneOfs
+	}
+	return ref
+}
+
+// oneofSchema allows only one field of the oneof, none of them is also allowed when oneof is not required.
+func oneofSchema(od protoreflect.OneofDescriptor) map[string]any {
+	variants := make([]any, 0, od.Fields().Len())
+	for i := 0; i < od.Fields().Len(); i++ {
+		variants = append(variants, map[string]any{
+			"required": []string{string(od.Fields().Get(i).Name())},
+		})
+	}
+	oneOf := append([]any{}, variants...)
+	required, _ := proto.GetExtension(od.Options(), pgv.E_Required).(bool)
+	if !required {
+		oneOf = append(oneOf, map[string]any{
+			"not": map[string]any{"anyOf": variants},
+		})
+	}
+	return map[string]any{"oneOf": oneOf}
+}
+
+// fieldSchema gives schema of a field with its validate constraints and tells if field is required.
+func (g *schemaGenerator) fieldSchema(fd protoreflect.FieldDescriptor) (map[string]any, bool) {
+	var fieldSchema map[string]any
+	switch {
+	case fd.IsMap():
+		fieldSchema = map[string]any{
+			"type":                 "object",
+			"additionalProperties": g.kindSchema(fd.MapValue()),
+		}
+	case fd.IsList():
+		fieldSchema = map[string]any{
+			"type":  "array",
+			"items": g.kindSchema(fd),
+		}
+	default:
+		fieldSchema = g.kindSchema(fd)
+	}
+	rules, _ := proto.GetExtension(fd.Options(), pgv.E_Rules).(*pgv.FieldRules)
+	if rules == nil {
+		return fieldSchema, false
+	}
+	return fieldSchema, applyRules(fieldSchema, fd, rules)
+}
+
+func (g *schemaGenerator) kindSchema(fd protoreflect.FieldDescriptor) map[string]any {
+	switch fd.Kind() {
+	case protoreflect.BoolKind:
+		return map[string]any{"type": "boolean"}
+	case protoreflect.StringKind:
+		return map[string]any{"type": "string"}
+	case protoreflect.BytesKind:
+		return map[string]any{"type": "string", "contentEncoding": "base64"}
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind:
+		return map[string]any{"type": "integer"}
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind:
+		return map[string]any{"type": "integer", "minimum": 0}
+	case protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		// protojson write 64 bits integers as strings
+		return map[string]any{"type": []string{"integer", "string"}, "pattern": "^-?[0-9]+$"}
+	case protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		return map[string]any{"type": []string{"integer", "string"}, "pattern": "^[0-9]+$", "minimum": 0}
+	case protoreflect.FloatKind, protoreflect.DoubleKind:
+		return map[string]any{"type": "number"}
+	case protoreflect.EnumKind:
+		return map[string]any{"type": "string", "enum": enumNames(fd.Enum(), nil)}
+	case protoreflect.MessageKind, protoreflect.GroupKind:
+		return g.messageSchema(fd.Message())
+	}
+	return map[string]any{}
+}
+
+// enumNames gives names of enum values, only values accepted by keep are given when keep is not nil.
+func enumNames(ed protoreflect.EnumDescriptor, keep func(number int32) bool) []string {
+	names := make([]string, 0, ed.Values().Len())
+	for i := 0; i < ed.Values().Len(); i++ {
+		value := ed.Values().Get(i)
+		if keep != nil && !keep(int32(value.Number())) {
+			continue
+		}
+		names = append(names, string(value.Name()))
+	}
+	return names
+}
+
+// applyRules translate validate rules to json schema keywords, rules which can't be expressed are skipped.
+// It tells if field is required.
+func applyRules(fieldSchema map[string]any, fd protoreflect.FieldDescriptor, rules *pgv.FieldRules) bool {
+	required := rules.GetMessage().GetRequired()
+	switch r := rules.GetType().(type) {
+	case *pgv.FieldRules_String_:
+		applyStringRules(fieldSchema, r.String_)
+	case *pgv.FieldRules_Enum:
+		in := r.Enum.GetIn()
+		notIn := r.Enum.GetNotIn()
+		if len(in) > 0 || len(notIn) > 0 {
+			fieldSchema["enum"] = enumNames(fd.Enum(), func(number int32) bool {
+				return (len(in) == 0 || containsInt32(in, number)) && !containsInt32(notIn, number)
+			})
+		}
+	case *pgv.FieldRules_Repeated:
+		setIfPositive(fieldSchema, "minItems", r.Repeated.GetMinItems())
+		setIfPositive(fieldSchema, "maxItems", r.Repeated.GetMaxItems())
+		if r.Repeated.GetUnique() {
+			fieldSchema["uniqueItems"] = true
+		}
+		if items, ok := fieldSchema["items"].(map[string]any); ok && r.Repeated.GetItems() != nil {
+			applyRules(items, fd, r.Repeated.GetItems())
+		}
+	case *pgv.FieldRules_Map:
+		setIfPositive(fieldSchema, "minProperties", r.Map.GetMinPairs())
+		setIfPositive(fieldSchema, "maxProperties", r.Map.GetMaxPairs())
+	case *pgv.FieldRules_Duration:
+		required = required || r.Duration.GetRequired()
+	case *pgv.FieldRules_Timestamp:
+		required = required || r.Timestamp.GetRequired()
+	case *pgv.FieldRules_Any:
+		required = required || r.Any.GetRequired()
+	case nil:
+	default:
+		applyNumericRules(fieldSchema, rules)
+	}
+	return required
+}
+
+func applyStringRules(fieldSchema map[string]any, rules *pgv.StringRules) {
+	if rules.Len != nil {
+		fieldSchema["minLength"] = rules.GetLen()
+		fieldSchema["maxLength"] = rules.GetLen()
+	}
+	if rules.MinLen != nil {
+		fieldSchema["minLength"] = rules.GetMinLen()
+	}
+	if rules.MaxLen != nil {
+		fieldSchema["maxLength"] = rules.GetMaxLen()
+	}
+	if rules.Const != nil {
+		fieldSchema["const"] = rules.GetConst()
+	}
+	if len(rules.GetIn()) > 0 {
+		fieldSchema["enum"] = rules.GetIn()
+	}
+	if rules.Pattern != nil {
+		fieldSchema["pattern"] = rules.GetPattern()
+	}
+	switch {
+	case rules.GetIp():
+		fieldSchema["anyOf"] = []any{
+			map[string]any{"format": "ipv4"},
+			map[string]any{"format": "ipv6"},
+		}
+	case rules.GetIpv4():
+		fieldSchema["format"] = "ipv4"
+	case rules.GetIpv6():
+		fieldSchema["format"] = "ipv6"
+	case rules.GetHostname():
+		fieldSchema["format"] = "hostname"
+	case rules.GetEmail():
+		fieldSchema["format"] = "email"
+	case rules.GetUri():
+		fieldSchema["format"] = "uri"
+	case rules.GetUuid():
+		fieldSchema["format"] = "uuid"
+	}
+}
+
+// applyNumericRules read numeric rules by reflection as all of them share the same field names.
+func applyNumericRules(fieldSchema map[string]any, rules *pgv.FieldRules) {
+	rulesMsg := rules.ProtoReflect()
+	typeField := rulesMsg.WhichOneof(rulesMsg.Descriptor().Oneofs().ByName("type"))
+	if typeField == nil || typeField.Kind() != protoreflect.MessageKind {
+		return
+	}
+	numRules := rulesMsg.Get(typeField).Message()
+	for name, key := range numericRulesSchemaKeys {
+		fd := numRules.Descriptor().Fields().ByName(name)
+		if fd == nil || !numRules.Has(fd) {
+			continue
+		}
+		value := numRules.Get(fd)
+		if !fd.IsList() {
+			fieldSchema[key] = value.Interface()
+			continue
+		}
+		values := make([]any, value.List().Len())
+		for i := range values {
+			values[i] = value.List().Get(i).Interface()
+		}
+		fieldSchema[key] = values
+	}
+}
+
+func setIfPositive(fieldSchema map[string]any, key string, value uint64) {
+	if value > 0 {
+		fieldSchema[key] = value
+	}
+}
+
+func containsInt32(values []int32, value int32) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func init() {
+	desc := "Generate json schema of manifests for entry (default), healthcheck or member, for editor completion and validation."
+	cmd, err := parser.AddCommand(
+		"schema",
+		desc,
+		desc,
+		&schema)
+	if err != nil {
+		panic(err)
+	}
+	cmd.Aliases = []string{"json-schema"}
+}
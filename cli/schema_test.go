@@ -0,0 +1,61 @@
+package cli
+
+import (
+	"reflect"
+	"testing"
+
+	gslbsvc "github.com/orange-cloudfoundry/gsloc-go-sdk/gsloc/services/gslb/v1"
+)
+
+func TestJsonSchema(t *testing.T) {
+	schema := JsonSchema(&gslbsvc.SetEntryRequest{})
+	if schema["$ref"] != "#/definitions/gsloc.services.gslb.v1.SetEntryRequest" {
+		t.Fatalf("Unexpected root ref %v", schema["$ref"])
+	}
+	definitions := schema["definitions"].(map[string]any)
+	definition := func(name string) map[string]any {
+		def, ok := definitions[name].(map[string]any)
+		if !ok {
+			t.Fatalf("Definition %s not found", name)
+		}
+		return def
+	}
+	property := func(def map[string]any, name string) map[string]any {
+		prop, ok := def["properties"].(map[string]any)[name].(map[string]any)
+		if !ok {
+			t.Fatalf("Property %s not found in %s", name, def["title"])
+		}
+		return prop
+	}
+
+	entry := definition("gsloc.api.config.entries.v1.Entry")
+	algo := property(entry, "lb_algo_preferred")
+	if !reflect.DeepEqual(algo["enum"], []string{"ROUND_ROBIN", "TOPOLOGY", "RATIO", "RANDOM"}) {
+		t.Errorf("Unexpected lb algo enum %v", algo["enum"])
+	}
+
+	member := definition("gsloc.api.config.entries.v1.Member")
+	if property(member, "dc")["minLength"] != uint64(1) {
+		t.Errorf("Expected dc to have a min length, got %v", property(member, "dc"))
+	}
+	if _, ok := property(member, "ip")["anyOf"]; !ok {
+		t.Errorf("Expected ip to be constrained to ip formats, got %v", property(member, "ip"))
+	}
+
+	hc := definition("gsloc.api.config.healthchecks.v1.HealthCheck")
+	oneOf, ok := hc["oneOf"].([]any)
+	if !ok || len(oneOf) != 7 {
+		t.Fatalf("Expected oneOf with a variant per health checker, got %v", hc["oneOf"])
+	}
+	if !reflect.DeepEqual(oneOf[0], map[string]any{"required": []string{"http_health_check"}}) {
+		t.Errorf("Unexpected first variant %v", oneOf[0])
+	}
+	if _, ok := property(hc, "interval")["pattern"]; !ok {
+		t.Errorf("Expected duration to be a string with pattern, got %v", property(hc, "interval"))
+	}
+
+	req := definition("gsloc.services.gslb.v1.SetEntryRequest")
+	if _, ok := req["additionalProperties"]; ok {
+		t.Errorf("Expected additional properties to be allowed for json names of fields")
+	}
+}
@@ -0,0 +1,55 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ArthurHlt/go-flags"
+	"github.com/orange-cloudfoundry/gsloc-go-sdk/gsloc/api/config/entries/v1"
+	hcconf "github.com/orange-cloudfoundry/gsloc-go-sdk/gsloc/api/config/healthchecks/v1"
+	gslbsvc "github.com/orange-cloudfoundry/gsloc-go-sdk/gsloc/services/gslb/v1"
+)
+
+func TestSetHealthcheckFromFile(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "hc.yml")
+	err := os.WriteFile(file, []byte(`timeout: 5s
+interval: 30s
+port: 443
+tcp_health_check:
+  send:
+    text: ping
+`), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fake := &fakeClient{entries: map[string]*gslbsvc.GetEntryResponse{
+		"a.example.com.": {
+			Entry: &entries.Entry{Fqdn: "a.example.com."},
+			Healthcheck: &hcconf.HealthCheck{
+				HealthChecker: &hcconf.HealthCheck_NoHealthCheck{NoHealthCheck: &hcconf.NoHealthCheck{}},
+			},
+		},
+	}}
+	cmd := &SetHealthcheck{
+		File:     flags.Filename(file),
+		FQDN:     &FQDN{content: "a.example.com"},
+		Strategy: "MERGE",
+		Force:    true,
+	}
+	cmd.HcType = "HTTP"
+	cmd.SetClient(fake)
+	err = cmd.Execute(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hc := fake.entries["a.example.com."].GetHealthcheck()
+	if hc.GetPort() != 443 || hc.GetTcpHealthCheck().GetSend().GetText() != "ping" || hc.GetNoHealthCheck() != nil {
+		t.Errorf("Expected healthcheck from file to be set, got %v", hc)
+	}
+
+	cmd.File = flags.Filename(filepath.Join(t.TempDir(), "missing.yml"))
+	if cmd.Execute(nil) == nil {
+		t.Error("Expected an error when file does not exist")
+	}
+}
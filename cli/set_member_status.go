@@ -8,16 +8,20 @@ import (
 )
 
 type SetMemberStatus struct {
-	FQDN *FQDN  `short:"f" long:"fqdn" description:"FQDN of the entry fd."`
+	FQDN *FQDN  `short:"f" long:"fqdn" description:"FQDN of the entry."`
 	Ip   string `short:"i" long:"ip" description:"IP of the member to disable."`
 
-	DC     string   `short:"d" long:"dc" description:"Datacenter of the member to add."`
-	Tags   []string `short:"t" long:"tag" description:"Filter by tag(s) (can be set multiple times)."`
-	Prefix string   `short:"p" long:"prefix" description:"Filter by prefix/fqdn."`
-	DryRun bool     `long:"dry-run" description:"Do not apply changes, just show what would be done."`
-	State  string   `short:"s" long:"state" description:"State to set." choice:"enable" choice:"disable" required:"true"`
-	Force  bool     `long:"force" description:"Force create entry without confirmation"`
-	Json   bool     `short:"j" long:"json" description:"Format in json instead of human table readable."`
+	DC          string   `short:"d" long:"dc" description:"Datacenter of the member to add."`
+	Tags        []string `short:"t" long:"tag" description:"Filter by tag(s) (can be set multiple times)."`
+	Prefix      string   `short:"p" long:"prefix" description:"Filter by prefix/fqdn."`
+	All         bool     `long:"all" description:"Allow to change every member on server when no filter is given."`
+	MaxAffected int      `long:"max-affected" description:"Abort when more than this number of members would be changed, 0 means no limit." default:"0"`
+	DryRun      bool     `long:"dry-run" description:"Do not apply changes, just show what would be done."`
+	State       string   `short:"s" long:"state" description:"State to set." choice:"enable" choice:"disable" required:"true"`
+	Force       bool     `long:"force" description:"Apply changes without confirmation"`
+
+	OutputFormat
+
 	client gslbsvc.GSLBClient
 }
 
@@ -28,34 +32,109 @@ func (c *SetMemberStatus) SetClient(client gslbsvc.GSLBClient) {
 var setMemberStatus SetMemberStatus
 
 func (c *SetMemberStatus) Execute([]string) error {
+	if !c.FQDN.IsEmpty() && c.Prefix != "" {
+		return fmt.Errorf("--fqdn and --prefix can not be used together")
+	}
+	if c.FQDN.IsEmpty() && c.Ip == "" && c.DC == "" && len(c.Tags) == 0 && c.Prefix == "" && !c.All {
+		return fmt.Errorf("no filter given, this would change every member on server, use --all if this is intended")
+	}
 	state := gslbsvc.MemberState_ENABLED
 	if c.State == "disable" {
 		state = gslbsvc.MemberState_DISABLED
 	}
-	resp, err := c.client.SetMembersStatus(context.Background(), &gslbsvc.SetMembersStatusRequest{
-		Prefix: c.Prefix,
-		Ip:     c.Ip,
-		Dc:     c.DC,
-		Tags:   c.Tags,
-		Status: state,
-		DryRun: c.DryRun,
-	})
+	makeReq := func(dryRun bool) *gslbsvc.SetMembersStatusRequest {
+		req := &gslbsvc.SetMembersStatusRequest{
+			Prefix: c.Prefix,
+			Ip:     c.Ip,
+			Dc:     c.DC,
+			Tags:   c.Tags,
+			Status: state,
+			DryRun: dryRun,
+		}
+		if !c.FQDN.IsEmpty() {
+			req.Prefix = c.FQDN.String()
+		}
+		return req
+	}
+
+	dryRunResp, err := c.client.SetMembersStatus(context.Background(), makeReq(true))
+	if err != nil {
+		return err
+	}
+	if !c.FQDN.IsEmpty() {
+		for _, info := range dryRunResp.GetUpdated() {
+			if info.GetFqdn() != c.FQDN.String() {
+				return fmt.Errorf("refusing to change members, entry %s would be changed as well as %s", info.GetFqdn(), c.FQDN)
+			}
+		}
+	}
+	nbAffected := AffectedMembers(dryRunResp)
+	if c.DryRun || nbAffected == 0 {
+		return c.print(dryRunResp, state, true)
+	}
+	if c.MaxAffected > 0 && nbAffected > c.MaxAffected {
+		return fmt.Errorf("%d members would be changed which is more than --max-affected %d, aborting", nbAffected, c.MaxAffected)
+	}
+
+	if !c.IsHuman() {
+		msg.UseStderr()
+	}
+	err = c.printTable(dryRunResp, state, true)
+	msg.UseStdout()
 	if err != nil {
 		return err
 	}
-	if c.Json {
-		return PrintProtoJson(resp)
+	confirm, err := Confirm(c.Force)
+	if err != nil {
+		return err
+	}
+	if !confirm {
+		return nil
+	}
+
+	resp, err := c.client.SetMembersStatus(context.Background(), makeReq(false))
+	if err != nil {
+		return err
+	}
+	return c.print(resp, state, false)
+}
+
+// AffectedMembers gives number of members changed by a set members status call.
+func AffectedMembers(resp *gslbsvc.SetMembersStatusResponse) int {
+	nb := 0
+	for _, info := range resp.GetUpdated() {
+		nb += len(info.GetIps())
+	}
+	return nb
+}
+
+func (c *SetMemberStatus) print(resp *gslbsvc.SetMembersStatusResponse, state gslbsvc.MemberState, dryRun bool) error {
+	names := make([]string, len(resp.GetUpdated()))
+	for i, info := range resp.GetUpdated() {
+		names[i] = info.GetFqdn()
+	}
+	return c.Print(resp, names, func(bool) error {
+		return c.printTable(resp, state, dryRun)
+	})
+}
+
+func (c *SetMemberStatus) printTable(resp *gslbsvc.SetMembersStatusResponse, state gslbsvc.MemberState, dryRun bool) error {
+	if len(resp.GetUpdated()) == 0 {
+		msg.Info("No members match given filters, nothing to do.")
+		return nil
 	}
 	stateText := msg.Green("Enabled").String()
+	verbText := msg.Green("enable").String()
 	if state == gslbsvc.MemberState_DISABLED {
 		stateText = msg.Red("Disabled").String()
+		verbText = msg.Red("disable").String()
 	}
-	if c.DryRun {
+	if dryRun {
 		msg.Info("This is a dry run, nothing has been done.")
-		msg.Infof("This will %s those members:", stateText)
+		msg.Infof("This will %s those %d members:", verbText, AffectedMembers(resp))
 	} else {
 		msg.Warning("This is not a dry run, changes has been applied.")
-		msg.Warning("You may wait few seconds before changes are applied.")
+		msg.Warning("You may wait few seconds before changes are applied, use wait command to block until members reach their state.")
 		msg.Infof("Members below has been %s :", stateText)
 	}
 	table := MakeTableWriter([]string{"FQDN", "IPs"})
@@ -0,0 +1,52 @@
+package cli
+
+import (
+	"testing"
+
+	gslbsvc "github.com/orange-cloudfoundry/gsloc-go-sdk/gsloc/services/gslb/v1"
+)
+
+func TestSetMemberStatusSafety(t *testing.T) {
+	client := &fakeClient{
+		setMembersStatusResp: &gslbsvc.SetMembersStatusResponse{
+			Updated: []*gslbsvc.SetMembersStatusResponse_Info{
+				{Fqdn: "a.example.com.", Ips: []string{"10.0.0.1", "10.0.0.2"}},
+				{Fqdn: "b.example.com.", Ips: []string{"10.0.0.1"}},
+			},
+		},
+	}
+	cmd := &SetMemberStatus{
+		State:  "disable",
+		Force:  true,
+		client: client,
+	}
+	if err := cmd.Execute(nil); err == nil || len(client.setMembersStatusReqs) > 0 {
+		t.Fatalf("Expected empty filters to be refused without any call")
+	}
+
+	cmd.DC = "dc1"
+	cmd.MaxAffected = 2
+	if err := cmd.Execute(nil); err == nil {
+		t.Fatalf("Expected change on 3 members to be aborted")
+	}
+	if len(client.setMembersStatusReqs) != 1 || !client.setMembersStatusReqs[0].DryRun {
+		t.Fatalf("Expected only a dry run but got %v", client.setMembersStatusReqs)
+	}
+
+	cmd.MaxAffected = 0
+	if err := cmd.Execute(nil); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if len(client.setMembersStatusReqs) != 3 || !client.setMembersStatusReqs[1].DryRun || client.setMembersStatusReqs[2].DryRun {
+		t.Fatalf("Expected a dry run followed by a real call but got %v", client.setMembersStatusReqs)
+	}
+
+	cmd.DC = ""
+	cmd.FQDN = &FQDN{content: "a.example.com"}
+	if err := cmd.Execute(nil); err == nil {
+		t.Fatalf("Expected change to be refused when other entries than fqdn match")
+	}
+	if req := client.setMembersStatusReqs[3]; req.Prefix != "a.example.com." || !req.DryRun {
+		t.Errorf("Expected fqdn to be sent as prefix in a dry run but got %v", req)
+	}
+}
@@ -0,0 +1,414 @@
+package cli
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/ArthurHlt/go-flags"
+	msg "github.com/ArthurHlt/messages"
+	"github.com/orange-cloudfoundry/gsloc-cli/app"
+	gslbsvc "github.com/orange-cloudfoundry/gsloc-go-sdk/gsloc/services/gslb/v1"
+	"io"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"time"
+)
+
+type StatusReport struct {
+	Replay flags.Filename `short:"r" long:"replay" description:"Replay status events in json lines made by entries-status --watch --output json or by --record instead of sampling target"`
+	Record flags.Filename `long:"record" description:"Write sampled status events in json lines to this file, it can be replayed later with --replay"`
+	End    string         `long:"end" description:"End of report window when replaying, RFC3339 time (default: time of last event)"`
+
+	Duration time.Duration `short:"d" long:"duration" description:"Sampling window, ctrl+c stops sampling earlier and gives report." default:"10m"`
+	Interval time.Duration `short:"n" long:"interval" description:"Interval between two samples." default:"5s"`
+
+	Tags   []string `short:"t" long:"tag" description:"Filter by tag(s) when sampling, events replayed have no tags (can be set multiple times)."`
+	Prefix string   `short:"p" long:"prefix" description:"Filter by prefix."`
+
+	FlapTransitions int           `long:"flap-transitions" description:"Number of transitions in flap window to flag a member as flapping." default:"4"`
+	FlapWindow      time.Duration `long:"flap-window" description:"Window in which flap transitions are counted." default:"10m"`
+
+	OutputFormat
+
+	// client is created from target when not set, replay does not need a target
+	client gslbsvc.GSLBClient
+}
+
+var statusReport StatusReport
+
+// MemberReport is the health history of a member over report window,
+// time disabled by user is not part of observed time.
+type MemberReport struct {
+	Fqdn                 string         `json:"fqdn"`
+	Ip                   string         `json:"ip"`
+	Dc                   string         `json:"dc"`
+	Status               string         `json:"status"`
+	UptimePercent        float64        `json:"uptime_percent"`
+	ObservedSeconds      float64        `json:"observed_seconds"`
+	Transitions          int            `json:"transitions"`
+	LongestOutageSeconds float64        `json:"longest_outage_seconds"`
+	LongestOutageStart   *time.Time     `json:"longest_outage_start,omitempty"`
+	FailureReasons       []*ReasonCount `json:"failure_reasons"`
+	Flapping             bool           `json:"flapping"`
+}
+
+// ReasonCount is the number of times member went in failure with a reason.
+type ReasonCount struct {
+	Reason string `json:"reason"`
+	Count  int    `json:"count"`
+}
+
+func (c *StatusReport) Execute([]string) error {
+	if c.FlapTransitions < 2 {
+		return fmt.Errorf("flap transitions must be at least 2")
+	}
+	var events []*StatusEvent
+	var start, end time.Time
+	var err error
+	if c.Replay != "" {
+		if len(c.Tags) > 0 {
+			return fmt.Errorf("--tag can't be used with --replay as status events have no tags, use --prefix instead")
+		}
+		events, err = c.replay()
+		if err != nil {
+			return err
+		}
+		if len(events) == 0 {
+			return fmt.Errorf("no status event found in %s", c.Replay)
+		}
+		start, end = events[0].Time, events[len(events)-1].Time
+		if c.End != "" {
+			end, err = time.Parse(time.RFC3339, c.End)
+			if err != nil {
+				return fmt.Errorf("invalid end time: %s", err)
+			}
+			if end.Before(start) {
+				return fmt.Errorf("end time %s is before first event at %s", c.End, start.Format(time.RFC3339))
+			}
+		}
+	} else {
+		start = time.Now()
+		events, err = c.sample()
+		if err != nil {
+			return err
+		}
+		end = time.Now()
+	}
+
+	reports := BuildStatusReport(events, end, c.FlapTransitions, c.FlapWindow)
+	names := make([]string, len(reports))
+	for i, report := range reports {
+		names[i] = MemberStatusKey(report.Fqdn, report.Ip)
+	}
+	return c.Print(reports, names, func(bool) error {
+		return c.printTable(reports, start, end)
+	})
+}
+
+// sample poll entries status during window or until interrupted, events are recorded when asked.
+func (c *StatusReport) sample() ([]*StatusEvent, error) {
+	if c.Interval <= 0 {
+		return nil, fmt.Errorf("interval must be positive")
+	}
+	if c.client == nil {
+		clientConn, err := app.CreateConnFromFile(ExpandConfigPath(), opts.Target)
+		if err != nil {
+			return nil, err
+		}
+		defer clientConn.Close() // nolint:errcheck
+		c.client = app.MakeClient(clientConn)
+	}
+	var record io.Writer
+	if c.Record != "" {
+		f, err := os.Create(string(c.Record))
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close() // nolint:errcheck
+		record = f
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer cancel()
+	ctx, cancelWindow := context.WithTimeout(ctx, c.Duration)
+	defer cancelWindow()
+
+	msg.UseStderr()
+	msg.Infof("Sampling status every %s during %s (ctrl+c to stop and get report)", c.Interval, c.Duration)
+	msg.UseStdout()
+	events := make([]*StatusEvent, 0)
+	var previous []*gslbsvc.GetEntryStatusResponse
+	for {
+		resp, err := c.client.ListEntriesStatus(ctx, &gslbsvc.ListEntriesStatusRequest{
+			Tags:   c.Tags,
+			Prefix: c.Prefix,
+		})
+		if ctx.Err() != nil {
+			return events, nil
+		}
+		if err != nil {
+			msg.UseStderr()
+			msg.Error(fmt.Sprintf("Failed to get status: %s", err.Error()))
+			msg.UseStdout()
+		} else {
+			newEvents := StatusEvents(previous, resp.GetEntriesStatus(), time.Now())
+			if record != nil {
+				err = writeStatusEvents(record, newEvents, true)
+				if err != nil {
+					return nil, err
+				}
+			}
+			events = append(events, newEvents...)
+			previous = resp.GetEntriesStatus()
+		}
+		select {
+		case <-ctx.Done():
+			return events, nil
+		case <-time.After(c.Interval):
+		}
+	}
+}
+
+// replay read status events from file, one json event per line.
+func (c *StatusReport) replay() ([]*StatusEvent, error) {
+	f, err := os.Open(string(c.Replay))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close() // nolint:errcheck
+	events, err := ReadStatusEvents(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %s", c.Replay, err)
+	}
+	filtered := make([]*StatusEvent, 0, len(events))
+	for _, event := range events {
+		if strings.HasPrefix(event.Fqdn, c.Prefix) {
+			filtered = append(filtered, event)
+		}
+	}
+	return filtered, nil
+}
+
+// ReadStatusEvents read status events written in json lines, empty lines are ignored.
+func ReadStatusEvents(r io.Reader) ([]*StatusEvent, error) {
+	events := make([]*StatusEvent, 0)
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	line := 0
+	for scanner.Scan() {
+		line++
+		if strings.TrimSpace(scanner.Text()) == "" {
+			continue
+		}
+		event := &StatusEvent{}
+		err := json.Unmarshal(scanner.Bytes(), event)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %s", line, err)
+		}
+		events = append(events, event)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].Time.Before(events[j].Time)
+	})
+	return events, nil
+}
+
+// memberHistory follows status of a member while events are replayed.
+type memberHistory struct {
+	report *MemberReport
+
+	status      string
+	since       time.Time
+	outageStart time.Time
+
+	online, observed, longestOutage time.Duration
+	transitions                     []time.Time
+	reasons                         map[string]int
+}
+
+// advance account time spent in current status until t.
+func (h *memberHistory) advance(t time.Time) {
+	d := t.Sub(h.since)
+	switch h.status {
+	case "", StatusDisabled:
+	case gslbsvc.MemberStatus_ONLINE.String():
+		h.online += d
+		h.observed += d
+	default:
+		h.observed += d
+	}
+	h.since = t
+}
+
+func (h *memberHistory) setStatus(status string, t time.Time) {
+	failing := status != "" && status != StatusDisabled && status != gslbsvc.MemberStatus_ONLINE.String()
+	if failing && h.outageStart.IsZero() {
+		h.outageStart = t
+	}
+	if !failing && !h.outageStart.IsZero() {
+		if outage := t.Sub(h.outageStart); outage > h.longestOutage {
+			h.longestOutage = outage
+			outageStart := h.outageStart
+			h.report.LongestOutageStart = &outageStart
+		}
+		h.outageStart = time.Time{}
+	}
+	h.status = status
+}
+
+// flapping tells if at least n transitions happened in window.
+func (h *memberHistory) flapping(n int, window time.Duration) bool {
+	for i := 0; i+n-1 < len(h.transitions); i++ {
+		if h.transitions[i+n-1].Sub(h.transitions[i]) <= window {
+			return true
+		}
+	}
+	return false
+}
+
+// BuildStatusReport gives health history of each member from status events sorted by time, window ends at end
+// and events after it are ignored.
+// A member is flapping when flapTransitions transitions happened in flapWindow.
+func BuildStatusReport(events []*StatusEvent, end time.Time, flapTransitions int, flapWindow time.Duration) []*MemberReport {
+	histories := make(map[string]*memberHistory)
+	for _, event := range events {
+		if event.Time.After(end) {
+			break
+		}
+		key := MemberStatusKey(event.Fqdn, event.Ip)
+		h, ok := histories[key]
+		if !ok {
+			h = &memberHistory{
+				report:  &MemberReport{Fqdn: event.Fqdn, Ip: event.Ip, Dc: event.Dc},
+				since:   event.Time,
+				reasons: make(map[string]int),
+			}
+			histories[key] = h
+		}
+		h.advance(event.Time)
+		if event.From != "" && event.To != "" {
+			h.transitions = append(h.transitions, event.Time)
+		}
+		if event.To != "" && event.To != StatusDisabled && event.To != gslbsvc.MemberStatus_ONLINE.String() && event.Reason != "" {
+			h.reasons[event.Reason]++
+		}
+		h.setStatus(event.To, event.Time)
+	}
+
+	reports := make([]*MemberReport, 0, len(histories))
+	for _, h := range histories {
+		if end.After(h.since) {
+			h.advance(end)
+		}
+		status := h.status
+		// an outage still running at end of window is accounted
+		h.setStatus("", end)
+
+		report := h.report
+		report.Status = status
+		if status == "" {
+			report.Status = "REMOVED"
+		}
+		report.ObservedSeconds = h.observed.Seconds()
+		if h.observed > 0 {
+			report.UptimePercent = float64(h.online) * 100 / float64(h.observed)
+		}
+		report.Transitions = len(h.transitions)
+		report.LongestOutageSeconds = h.longestOutage.Seconds()
+		report.FailureReasons = make([]*ReasonCount, 0, len(h.reasons))
+		for reason, count := range h.reasons {
+			report.FailureReasons = append(report.FailureReasons, &ReasonCount{Reason: reason, Count: count})
+		}
+		sort.Slice(report.FailureReasons, func(i, j int) bool {
+			if report.FailureReasons[i].Count != report.FailureReasons[j].Count {
+				return report.FailureReasons[i].Count > report.FailureReasons[j].Count
+			}
+			return report.FailureReasons[i].Reason < report.FailureReasons[j].Reason
+		})
+		report.Flapping = h.flapping(flapTransitions, flapWindow)
+		reports = append(reports, report)
+	}
+	sort.Slice(reports, func(i, j int) bool {
+		if reports[i].Fqdn != reports[j].Fqdn {
+			return reports[i].Fqdn < reports[j].Fqdn
+		}
+		return reports[i].Ip < reports[j].Ip
+	})
+	return reports
+}
+
+func (c *StatusReport) printTable(reports []*MemberReport, start, end time.Time) error {
+	msg.Infof("Status report from %s to %s (%s)",
+		start.Format(time.RFC3339), end.Format(time.RFC3339), end.Sub(start).Round(time.Second))
+	if len(reports) == 0 {
+		msg.Info("No members found.")
+		return nil
+	}
+	table := MakeTableWriter([]string{"FQDN", "DC", "IP", "Status", "Uptime", "Transitions", "Longest outage", "Failure reasons"})
+	table.SetAutoWrapText(false)
+	nbFlapping := 0
+	for _, report := range reports {
+		ip := report.Ip
+		if report.Flapping {
+			nbFlapping++
+			ip += msg.Magenta(" [flapping]").String()
+		}
+		uptime := "-"
+		if report.ObservedSeconds > 0 {
+			uptime = fmt.Sprintf("%.2f%%", report.UptimePercent)
+			switch {
+			case report.UptimePercent == 100:
+				uptime = msg.Green(uptime).String()
+			case report.UptimePercent < 90:
+				uptime = msg.Red(uptime).String()
+			default:
+				uptime = msg.Yellow(uptime).String()
+			}
+		}
+		outage := "-"
+		if report.LongestOutageStart != nil {
+			outage = fmt.Sprintf("%s at %s",
+				time.Duration(report.LongestOutageSeconds*float64(time.Second)).Round(time.Second),
+				report.LongestOutageStart.Format(time.RFC3339))
+		}
+		reasons := make([]string, len(report.FailureReasons))
+		for i, reason := range report.FailureReasons {
+			reasons[i] = fmt.Sprintf("%s (x%d)", reason.Reason, reason.Count)
+		}
+		table.Append([]string{
+			report.Fqdn,
+			report.Dc,
+			ip,
+			report.Status,
+			uptime,
+			fmt.Sprint(report.Transitions),
+			outage,
+			strings.Join(reasons, "\n"),
+		})
+	}
+	table.Render()
+	if nbFlapping > 0 {
+		msg.Warning(fmt.Sprintf("%d members are flapping, they made at least %d transitions in %s.",
+			nbFlapping, c.FlapTransitions, c.FlapWindow))
+	}
+	return nil
+}
+
+func init() {
+	desc := "Report uptime, transitions, outages and failure reasons of members by sampling status or replaying recorded status events."
+	cmd, err := parser.AddCommand(
+		"status-report",
+		desc,
+		desc,
+		&statusReport)
+	if err != nil {
+		panic(err)
+	}
+	cmd.Aliases = []string{"sr"}
+}
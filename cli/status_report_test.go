@@ -0,0 +1,128 @@
+package cli
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ArthurHlt/go-flags"
+	gslbsvc "github.com/orange-cloudfoundry/gsloc-go-sdk/gsloc/services/gslb/v1"
+)
+
+func TestBuildStatusReport(t *testing.T) {
+	start := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	at := func(minutes int) time.Time {
+		return start.Add(time.Duration(minutes) * time.Minute)
+	}
+	events := []*StatusEvent{
+		{Time: at(0), Fqdn: "a.example.com.", Ip: "10.0.0.1", Dc: "dc1", To: "ONLINE"},
+		{Time: at(0), Fqdn: "a.example.com.", Ip: "10.0.0.2", Dc: "dc2", To: "ONLINE"},
+		{Time: at(10), Fqdn: "a.example.com.", Ip: "10.0.0.2", Dc: "dc2", From: "ONLINE", To: "CHECK_FAILED", Reason: "timeout"},
+		{Time: at(12), Fqdn: "a.example.com.", Ip: "10.0.0.2", Dc: "dc2", From: "CHECK_FAILED", To: "ONLINE"},
+		{Time: at(14), Fqdn: "a.example.com.", Ip: "10.0.0.2", Dc: "dc2", From: "ONLINE", To: "CHECK_FAILED", Reason: "timeout"},
+		{Time: at(15), Fqdn: "a.example.com.", Ip: "10.0.0.2", Dc: "dc2", From: "CHECK_FAILED", To: "CHECK_FAILED", Reason: "connection refused"},
+		{Time: at(20), Fqdn: "a.example.com.", Ip: "10.0.0.2", Dc: "dc2", From: "CHECK_FAILED", To: "ONLINE"},
+		{Time: at(30), Fqdn: "a.example.com.", Ip: "10.0.0.1", Dc: "dc1", From: "ONLINE", To: "DISABLED", Reason: "disabled entry"},
+	}
+
+	reports := BuildStatusReport(events, at(40), 4, 10*time.Minute)
+	if len(reports) != 2 {
+		t.Fatalf("Expected 2 reports, got %d", len(reports))
+	}
+	disabled, flapping := reports[0], reports[1]
+
+	if disabled.Status != StatusDisabled || disabled.UptimePercent != 100 || disabled.ObservedSeconds != 30*60 || disabled.Flapping {
+		t.Errorf("Expected time disabled to not be observed, got %+v", disabled)
+	}
+
+	if flapping.Transitions != 5 || !flapping.Flapping {
+		t.Errorf("Expected 5 transitions and flapping, got %+v", flapping)
+	}
+	if flapping.UptimePercent != 80 {
+		t.Errorf("Expected 80%% uptime, got %f", flapping.UptimePercent)
+	}
+	if flapping.LongestOutageSeconds != 6*60 || !flapping.LongestOutageStart.Equal(at(14)) {
+		t.Errorf("Expected longest outage of 6m at 14m, got %fs at %v", flapping.LongestOutageSeconds, flapping.LongestOutageStart)
+	}
+	if len(flapping.FailureReasons) != 2 || flapping.FailureReasons[0].Reason != "timeout" || flapping.FailureReasons[0].Count != 2 {
+		t.Errorf("Expected timeout to be the most recurring reason, got %v", flapping.FailureReasons)
+	}
+
+	if BuildStatusReport(events, at(40), 6, 10*time.Minute)[1].Flapping {
+		t.Error("Expected member to not be flapping with 6 transitions")
+	}
+}
+
+func TestBuildStatusReportEndCutsWindow(t *testing.T) {
+	start := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	events := []*StatusEvent{
+		{Time: start, Fqdn: "a.example.com.", Ip: "10.0.0.1", Dc: "dc1", To: "ONLINE"},
+		{Time: start.Add(time.Hour), Fqdn: "a.example.com.", Ip: "10.0.0.1", Dc: "dc1", From: "ONLINE", To: "CHECK_FAILED", Reason: "timeout"},
+		{Time: start.Add(2 * time.Hour), Fqdn: "a.example.com.", Ip: "10.0.0.1", Dc: "dc1", From: "CHECK_FAILED", To: "ONLINE"},
+	}
+
+	reports := BuildStatusReport(events, start.Add(30*time.Minute), 4, 10*time.Minute)
+	if len(reports) != 1 {
+		t.Fatalf("Expected 1 report, got %d", len(reports))
+	}
+	report := reports[0]
+	if report.UptimePercent != 100 || report.ObservedSeconds != 1800 {
+		t.Errorf("Expected 100%% uptime over 1800s, got %f%% over %fs", report.UptimePercent, report.ObservedSeconds)
+	}
+	if report.Status != "ONLINE" || report.Transitions != 0 || len(report.FailureReasons) != 0 || report.LongestOutageSeconds != 0 {
+		t.Errorf("Expected events after end to be ignored, got %+v", report)
+	}
+}
+
+func TestStatusReportRecordAndReplay(t *testing.T) {
+	online := []*gslbsvc.GetEntryStatusResponse{{
+		Fqdn:        "a.example.com.",
+		MembersIpv4: []*gslbsvc.MemberStatus{{Ip: "10.0.0.1", Dc: "dc1", Status: gslbsvc.MemberStatus_ONLINE}},
+	}}
+	failed := []*gslbsvc.GetEntryStatusResponse{{
+		Fqdn:        "a.example.com.",
+		MembersIpv4: []*gslbsvc.MemberStatus{{Ip: "10.0.0.1", Dc: "dc1", Status: gslbsvc.MemberStatus_CHECK_FAILED, FailureReason: "timeout"}},
+	}}
+	record := filepath.Join(t.TempDir(), "events.jsonl")
+	cmd := &StatusReport{
+		Record:   flags.Filename(record),
+		Duration: 200 * time.Millisecond,
+		Interval: 10 * time.Millisecond,
+		client:   &fakeClient{entriesStatus: [][]*gslbsvc.GetEntryStatusResponse{online, failed, online}},
+	}
+	events, err := cmd.sample()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 3 {
+		t.Fatalf("Expected 3 events, got %v", events)
+	}
+
+	content, err := os.ReadFile(record)
+	if err != nil {
+		t.Fatal(err)
+	}
+	replayed, err := ReadStatusEvents(bytes.NewReader(content))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(replayed) != len(events) {
+		t.Fatalf("Expected %d replayed events, got %d", len(events), len(replayed))
+	}
+	for i, event := range replayed {
+		if event.String() != events[i].String() {
+			t.Errorf("Expected replayed event %q, got %q", events[i], event)
+		}
+	}
+
+	if _, err := ReadStatusEvents(bytes.NewBufferString("\n{\"fqdn\": 1}\n")); err == nil {
+		t.Error("Expected an error on invalid event")
+	}
+
+	cmd = &StatusReport{Replay: flags.Filename(record), Tags: []string{"prod"}, FlapTransitions: 4}
+	if err := cmd.Execute(nil); err == nil {
+		t.Error("Expected an error when filtering replayed events by tag")
+	}
+}
@@ -24,13 +24,19 @@ func (n *FQDN) String() string {
 	return strings.ToLower(Fqdn(n.content))
 }
 
+// IsEmpty tells if fqdn has not been given, useful for optional positional fqdn.
+func (n *FQDN) IsEmpty() bool {
+	return n == nil || n.content == ""
+}
+
 func (n *FQDN) Complete(match string) []flags.Completion {
 	opts.ConfigPath = defaultConfigPath
 	if os.Getenv("GSLOC_CONFIG_PATH") != "" {
 		opts.ConfigPath = os.Getenv("GSLOC_CONFIG_PATH")
 	}
+	opts.Target = os.Getenv("GSLOC_TARGET")
 
-	clientConn, err := app.CreateConnFromFile(ExpandConfigPath())
+	clientConn, err := app.CreateConnFromFile(ExpandConfigPath(), opts.Target)
 	if err != nil {
 		return []flags.Completion{
 			{Item: "Error: " + err.Error()},
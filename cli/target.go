@@ -0,0 +1,166 @@
+package cli
+
+import (
+	"fmt"
+	"github.com/ArthurHlt/go-flags"
+	msg "github.com/ArthurHlt/messages"
+	"github.com/orange-cloudfoundry/gsloc-cli/app"
+	"os"
+	"strings"
+)
+
+type TargetName struct {
+	content string
+}
+
+func (n *TargetName) String() string {
+	return n.content
+}
+
+func (n *TargetName) Complete(match string) []flags.Completion {
+	opts.ConfigPath = defaultConfigPath
+	if os.Getenv("GSLOC_CONFIG_PATH") != "" {
+		opts.ConfigPath = os.Getenv("GSLOC_CONFIG_PATH")
+	}
+	targets, _, err := app.ListTargets(ExpandConfigPath())
+	if err != nil {
+		return []flags.Completion{
+			{Item: "Error: " + err.Error()},
+		}
+	}
+	completions := make([]flags.Completion, 0)
+	for _, target := range targets {
+		if !strings.HasPrefix(target.Name, match) {
+			continue
+		}
+		completions = append(completions, flags.Completion{
+			Item: target.Name,
+		})
+	}
+	return completions
+}
+
+func (n *TargetName) UnmarshalFlag(value string) error {
+	n.content = value
+	return nil
+}
+
+type TargetCmd struct{}
+
+type ListTargets struct {
+	OutputFormat
+}
+
+type UseTarget struct {
+	Name *TargetName `positional-args:"true" positional-arg-name:"'name'" required:"true"`
+}
+
+type DeleteTarget struct {
+	Name *TargetName `positional-args:"true" positional-arg-name:"'name'" required:"true"`
+}
+
+var targetCmd TargetCmd
+var listTargets ListTargets
+var useTarget UseTarget
+var deleteTarget DeleteTarget
+
+func (c *ListTargets) Execute([]string) error {
+	targets, current, err := app.ListTargets(ExpandConfigPath())
+	if err != nil {
+		return err
+	}
+	names := make([]string, len(targets))
+	for i, target := range targets {
+		names[i] = target.Name
+	}
+	return c.Print(targets, names, func(bool) error {
+		return c.printTable(targets, current)
+	})
+}
+
+func (c *ListTargets) printTable(targets []*app.Target, current string) error {
+	if len(targets) == 0 {
+		msg.Info("No targets found.")
+		return nil
+	}
+	table := MakeTableWriter([]string{"Current", "Name", "Host", "Username", "Skip SSL Validation"})
+	table.SetAutoWrapText(false)
+	for _, target := range targets {
+		isCurrent := ""
+		if target.Name == current {
+			isCurrent = "*"
+		}
+		table.Append([]string{
+			isCurrent,
+			target.Name,
+			target.Host,
+			target.Username,
+			fmt.Sprintf("%t", target.SkipVerify),
+		})
+	}
+	table.Render()
+	return nil
+}
+
+func (c *UseTarget) Execute([]string) error {
+	err := app.UseTarget(ExpandConfigPath(), c.Name.String())
+	if err != nil {
+		return err
+	}
+	msg.Successf("Target %s is now the current target.", msg.Cyan(c.Name))
+	return nil
+}
+
+func (c *DeleteTarget) Execute([]string) error {
+	err := app.DeleteTarget(ExpandConfigPath(), c.Name.String())
+	if err != nil {
+		return err
+	}
+	msg.Successf("Target %s deleted.", msg.Cyan(c.Name))
+	return nil
+}
+
+func init() {
+	desc := "Manage targets, login on a new target with global option --target."
+	cmd, err := parser.AddCommand(
+		"target",
+		desc,
+		desc,
+		&targetCmd)
+	if err != nil {
+		panic(err)
+	}
+	cmd.Aliases = []string{"t"}
+
+	desc = "List targets."
+	subCmd, err := cmd.AddCommand(
+		"list",
+		desc,
+		desc,
+		&listTargets)
+	if err != nil {
+		panic(err)
+	}
+	subCmd.Aliases = []string{"ls"}
+
+	desc = "Set current target."
+	_, err = cmd.AddCommand(
+		"use",
+		desc,
+		desc,
+		&useTarget)
+	if err != nil {
+		panic(err)
+	}
+
+	desc = "Delete target."
+	subCmd, err = cmd.AddCommand(
+		"delete",
+		desc,
+		desc,
+		&deleteTarget)
+	if err != nil {
+		panic(err)
+	}
+	subCmd.Aliases = []string{"rm"}
+}
@@ -0,0 +1,183 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/ArthurHlt/go-flags"
+	msg "github.com/ArthurHlt/messages"
+	"github.com/orange-cloudfoundry/gsloc-cli/healthcheck"
+	"github.com/orange-cloudfoundry/gsloc-go-sdk/gsloc/api/config/entries/v1"
+	hcconf "github.com/orange-cloudfoundry/gsloc-go-sdk/gsloc/api/config/healthchecks/v1"
+	gslbsvc "github.com/orange-cloudfoundry/gsloc-go-sdk/gsloc/services/gslb/v1"
+	"sync"
+	"time"
+)
+
+type TestHealthcheck struct {
+	File      flags.Filename `short:"f" long:"file" description:"Test healthcheck from a json or yml file definition instead of the one set on server"`
+	FromFlags bool           `long:"from-flags" description:"Test healthcheck made from healthcheck flags instead of the one set on server"`
+
+	Ips []string `long:"ip" description:"Only test these ips, ips which are not members can be given (can be set multiple time)"`
+
+	FQDN *FQDN `positional-args:"true" positional-arg-name:"'fqdn'" required:"true"`
+
+	HealthcheckFlags
+
+	client gslbsvc.GSLBClient
+}
+
+var testHealthcheck TestHealthcheck
+
+func (c *TestHealthcheck) SetClient(client gslbsvc.GSLBClient) {
+	c.client = client
+}
+
+// probeResult is the result of healthcheck against one member.
+type probeResult struct {
+	member   *entries.Member
+	err      error
+	duration time.Duration
+}
+
+func (c *TestHealthcheck) Execute([]string) error {
+	hc, err := c.loadHealthcheck()
+	if err != nil {
+		return err
+	}
+	members, err := c.members()
+	if err != nil {
+		return err
+	}
+	if len(members) == 0 {
+		return fmt.Errorf("no member to test for %s", c.FQDN)
+	}
+
+	msg.UseStderr()
+	msg.Infof("Testing healthcheck of %s from this host on %d members", msg.Cyan(c.FQDN), len(members))
+	msg.Printf("━━━━━\n")
+	msg.UseStdout()
+
+	results := probeMembers(context.Background(), hc, c.FQDN.String(), members)
+	table := MakeTableWriter([]string{"DC", "IP", "Result", "Time", "Reason"})
+	table.SetAutoWrapText(false)
+	failed := 0
+	for _, result := range results {
+		state := msg.Green("PASS").String()
+		reason := ""
+		switch {
+		case errors.Is(result.err, healthcheck.ErrNotTestable):
+			state = msg.Yellow("SKIP").String()
+			reason = result.err.Error()
+		case result.err != nil:
+			failed++
+			state = msg.Red("FAIL").String()
+			reason = result.err.Error()
+		}
+		if result.member.GetDisabled() {
+			reason = "disabled member " + reason
+		}
+		table.Append([]string{
+			result.member.GetDc(),
+			result.member.GetIp(),
+			state,
+			result.duration.Round(time.Millisecond).String(),
+			reason,
+		})
+	}
+	table.Render()
+	if failed > 0 {
+		return &ExitError{
+			Code:    1,
+			Message: fmt.Sprintf("Healthcheck failed on %d of %d members.", failed, len(results)),
+		}
+	}
+	msg.Success("Healthcheck passed on all tested members.")
+	return nil
+}
+
+// loadHealthcheck gives healthcheck from file, flags or server in this order.
+func (c *TestHealthcheck) loadHealthcheck() (*hcconf.HealthCheck, error) {
+	if c.File != "" {
+		hc, loaded, err := FileToProto[*hcconf.HealthCheck](string(c.File))
+		if err != nil {
+			return nil, err
+		}
+		if !loaded {
+			return nil, fmt.Errorf("no healthcheck found in file %s", c.File)
+		}
+		return hc, nil
+	}
+	if c.FromFlags {
+		return c.Build()
+	}
+	resp, err := c.client.GetHealthCheck(context.Background(), &gslbsvc.GetHealthCheckRequest{
+		Fqdn: c.FQDN.String(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resp.GetHealthcheck(), nil
+}
+
+// members gives members of entry filtered by given ips, entry may not exist when ips are given.
+func (c *TestHealthcheck) members() ([]*entries.Member, error) {
+	resp, err := c.client.ListMembers(context.Background(), &gslbsvc.ListMembersRequest{
+		Fqdn: c.FQDN.String(),
+	})
+	if err != nil && len(c.Ips) == 0 {
+		return nil, err
+	}
+	members := make([]*entries.Member, 0, len(resp.GetMembersIpv4())+len(resp.GetMembersIpv6()))
+	members = append(members, resp.GetMembersIpv4()...)
+	members = append(members, resp.GetMembersIpv6()...)
+	if len(c.Ips) == 0 {
+		return members, nil
+	}
+	filtered := make([]*entries.Member, 0, len(c.Ips))
+	for _, ip := range c.Ips {
+		member := &entries.Member{Ip: ip, Dc: "-"}
+		for _, m := range members {
+			if m.GetIp() == ip {
+				member = m
+				break
+			}
+		}
+		filtered = append(filtered, member)
+	}
+	return filtered, nil
+}
+
+// probeMembers runs healthcheck on all members in parallel, results are in members order.
+func probeMembers(ctx context.Context, hc *hcconf.HealthCheck, fqdn string, members []*entries.Member) []probeResult {
+	results := make([]probeResult, len(members))
+	wg := &sync.WaitGroup{}
+	for i, member := range members {
+		wg.Add(1)
+		go func(i int, member *entries.Member) {
+			defer wg.Done()
+			start := time.Now()
+			err := healthcheck.Probe(ctx, hc, fqdn, member.GetIp())
+			results[i] = probeResult{
+				member:   member,
+				err:      err,
+				duration: time.Since(start),
+			}
+		}(i, member)
+	}
+	wg.Wait()
+	return results
+}
+
+func init() {
+	desc := "Run healthcheck from this host against members to check it passes before setting it."
+	cmd, err := parser.AddCommand(
+		"test-healthcheck",
+		desc,
+		desc,
+		&testHealthcheck)
+	if err != nil {
+		panic(err)
+	}
+	cmd.Aliases = []string{"thc"}
+}
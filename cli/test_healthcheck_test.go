@@ -0,0 +1,59 @@
+package cli
+
+import (
+	"errors"
+	"net"
+	"testing"
+
+	"github.com/orange-cloudfoundry/gsloc-go-sdk/gsloc/api/config/entries/v1"
+	hcconf "github.com/orange-cloudfoundry/gsloc-go-sdk/gsloc/api/config/healthchecks/v1"
+	gslbsvc "github.com/orange-cloudfoundry/gsloc-go-sdk/gsloc/services/gslb/v1"
+)
+
+func TestTestHealthcheck(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close() // nolint:errcheck
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			conn.Close() // nolint:errcheck
+		}
+	}()
+
+	fake := &fakeClient{entries: map[string]*gslbsvc.GetEntryResponse{
+		"a.example.com.": {
+			Entry: &entries.Entry{
+				Fqdn: "a.example.com.",
+				MembersIpv4: []*entries.Member{
+					{Ip: "127.0.0.1", Dc: "dc1"},
+					{Ip: "127.0.0.2", Dc: "dc2"},
+				},
+			},
+			Healthcheck: &hcconf.HealthCheck{
+				Port:          uint32(listener.Addr().(*net.TCPAddr).Port),
+				HealthChecker: &hcconf.HealthCheck_TcpHealthCheck{TcpHealthCheck: &hcconf.TcpHealthCheck{}},
+			},
+		},
+	}}
+	cmd := &TestHealthcheck{FQDN: &FQDN{content: "a.example.com"}}
+	cmd.SetClient(fake)
+
+	// nothing listen on 127.0.0.2
+	err = cmd.Execute(nil)
+	var exitErr *ExitError
+	if !errors.As(err, &exitErr) || exitErr.Code != 1 {
+		t.Fatalf("Expected healthcheck to fail on one member, got %v", err)
+	}
+
+	cmd.Ips = []string{"127.0.0.1"}
+	err = cmd.Execute(nil)
+	if err != nil {
+		t.Errorf("Expected healthcheck to pass on 127.0.0.1, got %s", err)
+	}
+}
@@ -0,0 +1,240 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"github.com/orange-cloudfoundry/gsloc-cli/app"
+	gslbsvc "github.com/orange-cloudfoundry/gsloc-go-sdk/gsloc/services/gslb/v1"
+	"golang.org/x/term"
+	"os"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	ansiAltScreen     = "\033[?1049h"
+	ansiMainScreen    = "\033[?1049l"
+	ansiHideCursor    = "\033[?25l"
+	ansiShowCursor    = "\033[?25h"
+	ansiNoWrap        = "\033[?7l"
+	ansiWrap          = "\033[?7h"
+	ansiHome          = "\033[H"
+	ansiClearLine     = "\033[K"
+	ansiClearToBottom = "\033[J"
+)
+
+type Ui struct {
+	Tags     []string      `short:"t" long:"tag" description:"Filter by tag(s) (can be set multiple times), can be changed in ui."`
+	Prefix   string        `short:"p" long:"prefix" description:"Filter by prefix."`
+	Interval time.Duration `short:"n" long:"interval" description:"Interval between two refreshes." default:"5s"`
+
+	client gslbsvc.GSLBClient
+}
+
+type uiFetchResult struct {
+	ents []*gslbsvc.GetEntryStatusResponse
+	err  error
+}
+
+var ui Ui
+
+func (c *Ui) SetClient(client gslbsvc.GSLBClient) {
+	c.client = client
+}
+
+func (c *Ui) Execute([]string) error {
+	if !IsTerminal(os.Stdin) || !IsTerminal(os.Stdout) {
+		return fmt.Errorf("ui command must be run in a terminal")
+	}
+	if c.Interval <= 0 {
+		return fmt.Errorf("interval must be positive")
+	}
+	dcsResp, err := c.client.ListDcs(context.Background(), &gslbsvc.ListDcsRequest{})
+	if err != nil {
+		return err
+	}
+	model := &uiModel{
+		client:   c.client,
+		target:   opts.Target,
+		interval: c.Interval,
+		dcs:      dcsResp.GetDcs(),
+		tags:     c.Tags,
+		prefix:   c.Prefix,
+	}
+	if target, err := app.GetTarget(ExpandConfigPath(), opts.Target); err == nil {
+		model.target = target.Name
+	}
+
+	oldState, err := term.MakeRaw(int(os.Stdin.Fd()))
+	if err != nil {
+		return err
+	}
+	defer term.Restore(int(os.Stdin.Fd()), oldState) // nolint:errcheck
+	fmt.Print(ansiAltScreen + ansiHideCursor + ansiNoWrap)
+	defer fmt.Print(ansiWrap + ansiShowCursor + ansiMainScreen)
+
+	return c.loop(model)
+}
+
+func (c *Ui) loop(model *uiModel) error {
+	keys := make(chan string)
+	go readKeys(keys)
+
+	results := make(chan uiFetchResult, 1)
+	fetching, pendingFetch := false, false
+	fetch := func() {
+		if fetching {
+			pendingFetch = true
+			return
+		}
+		fetching = true
+		tags, prefix := model.tags, model.prefix
+		go func() {
+			ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+			defer cancel()
+			entsResp, err := c.client.ListEntriesStatus(ctx, &gslbsvc.ListEntriesStatusRequest{
+				Tags:   tags,
+				Prefix: prefix,
+			})
+			results <- uiFetchResult{ents: entsResp.GetEntriesStatus(), err: err}
+		}()
+	}
+
+	refreshTicker := time.NewTicker(c.Interval)
+	defer refreshTicker.Stop()
+	// size is polled to redraw on resize without relying on SIGWINCH which is not available everywhere
+	sizeTicker := time.NewTicker(500 * time.Millisecond)
+	defer sizeTicker.Stop()
+
+	fetch()
+	_, height := terminalSize()
+	draw(model, height)
+	for !model.quit {
+		select {
+		case key := <-keys:
+			if model.HandleKey(key, height-6) {
+				fetch()
+			}
+		case res := <-results:
+			fetching = false
+			if res.err != nil {
+				model.refreshErr = res.err
+			} else {
+				model.SetEntries(res.ents, time.Now())
+			}
+			if pendingFetch {
+				pendingFetch = false
+				fetch()
+			}
+		case <-refreshTicker.C:
+			fetch()
+		case <-sizeTicker.C:
+			if _, newHeight := terminalSize(); newHeight == height {
+				continue
+			}
+		}
+		_, height = terminalSize()
+		draw(model, height)
+	}
+	return nil
+}
+
+func terminalSize() (int, int) {
+	width, height, err := term.GetSize(int(os.Stdout.Fd()))
+	if err != nil {
+		return 80, 24
+	}
+	return width, height
+}
+
+func draw(model *uiModel, height int) {
+	buf := &strings.Builder{}
+	buf.WriteString(ansiHome)
+	for i, line := range model.Render(height) {
+		if i > 0 {
+			buf.WriteString("\r\n")
+		}
+		buf.WriteString(line)
+		buf.WriteString(ansiClearLine)
+	}
+	buf.WriteString(ansiClearToBottom)
+	fmt.Print(buf.String())
+}
+
+func readKeys(keys chan<- string) {
+	buf := make([]byte, 64)
+	for {
+		n, err := os.Stdin.Read(buf)
+		if err != nil {
+			keys <- "ctrl-c"
+			return
+		}
+		for _, key := range ParseKeys(buf[:n]) {
+			keys <- key
+		}
+	}
+}
+
+var escapeKeys = map[string]string{
+	"A":  "up",
+	"B":  "down",
+	"C":  "right",
+	"D":  "left",
+	"H":  "home",
+	"F":  "end",
+	"5~": "pgup",
+	"6~": "pgdown",
+}
+
+// ParseKeys convert bytes read from a terminal in raw mode to key names, printable characters are kept as is.
+func ParseKeys(b []byte) []string {
+	keys := make([]string, 0)
+	for i := 0; i < len(b); {
+		switch {
+		case b[i] == 0x1b && i+1 < len(b) && (b[i+1] == '[' || b[i+1] == 'O'):
+			end := i + 2
+			for end < len(b) && (b[end] < 0x40 || b[end] > 0x7e) {
+				end++
+			}
+			if end < len(b) {
+				if key, ok := escapeKeys[string(b[i+2:end+1])]; ok {
+					keys = append(keys, key)
+				}
+			}
+			i = end + 1
+		case b[i] == 0x1b:
+			keys = append(keys, "esc")
+			i++
+		case b[i] == 3:
+			keys = append(keys, "ctrl-c")
+			i++
+		case b[i] == '\r' || b[i] == '\n':
+			keys = append(keys, "enter")
+			i++
+		case b[i] == 127 || b[i] == 8:
+			keys = append(keys, "backspace")
+			i++
+		default:
+			r, size := utf8.DecodeRune(b[i:])
+			if r >= 0x20 && r != utf8.RuneError {
+				keys = append(keys, string(r))
+			}
+			i += size
+		}
+	}
+	return keys
+}
+
+func init() {
+	desc := "Full screen dashboard of entries status with members drill-down, needs a terminal."
+	cmd, err := parser.AddCommand(
+		"ui",
+		desc,
+		desc,
+		&ui)
+	if err != nil {
+		panic(err)
+	}
+	cmd.Aliases = []string{"dashboard"}
+}
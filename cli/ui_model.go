@@ -0,0 +1,488 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	msg "github.com/ArthurHlt/messages"
+	gslbsvc "github.com/orange-cloudfoundry/gsloc-go-sdk/gsloc/services/gslb/v1"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	uiViewEntries = iota
+	uiViewMembers
+)
+
+const (
+	uiInputNone = iota
+	uiInputFilter
+	uiInputTags
+	uiInputConfirm
+)
+
+const (
+	ansiReverse = "\033[7m"
+	ansiReset   = "\033[0m"
+)
+
+// uiModel is the state of ui command, it is rendered as lines and updated by keys
+// without knowing anything of the terminal.
+type uiModel struct {
+	client   gslbsvc.GSLBClient
+	target   string
+	interval time.Duration
+
+	dcs         []string
+	entries     []*gslbsvc.GetEntryStatusResponse
+	transitions map[string]string
+	lastRefresh time.Time
+	refreshErr  error
+
+	tags   []string
+	prefix string
+	filter string
+
+	view         int
+	cursor       int
+	offset       int
+	memberCursor int
+
+	input       int
+	inputBuffer string
+	confirmText string
+	onConfirm   func() error
+
+	status string
+	quit   bool
+}
+
+// SetEntries update entries status after a refresh, members which changed since previous refresh are highlighted.
+func (m *uiModel) SetEntries(ents []*gslbsvc.GetEntryStatusResponse, now time.Time) {
+	selected := m.selectedFqdn()
+	m.transitions = make(map[string]string)
+	if m.entries != nil {
+		for _, event := range StatusEvents(m.entries, ents, now) {
+			m.transitions[MemberStatusKey(event.Fqdn, event.Ip)] = event.From
+		}
+	}
+	sort.Slice(ents, func(i, j int) bool {
+		return ents[i].GetFqdn() < ents[j].GetFqdn()
+	})
+	m.entries = ents
+	m.lastRefresh = now
+	m.refreshErr = nil
+	for i, ent := range m.visibleEntries() {
+		if ent.GetFqdn() == selected {
+			m.cursor = i
+		}
+	}
+	m.clampCursors()
+}
+
+func (m *uiModel) visibleEntries() []*gslbsvc.GetEntryStatusResponse {
+	if m.filter == "" {
+		return m.entries
+	}
+	filter := strings.ToLower(m.filter)
+	ents := make([]*gslbsvc.GetEntryStatusResponse, 0)
+	for _, ent := range m.entries {
+		if strings.Contains(ent.GetFqdn(), filter) {
+			ents = append(ents, ent)
+		}
+	}
+	return ents
+}
+
+func (m *uiModel) selectedEntry() *gslbsvc.GetEntryStatusResponse {
+	ents := m.visibleEntries()
+	if m.cursor < 0 || m.cursor >= len(ents) {
+		return nil
+	}
+	return ents[m.cursor]
+}
+
+func (m *uiModel) selectedFqdn() string {
+	return m.selectedEntry().GetFqdn()
+}
+
+func (m *uiModel) selectedMember() *gslbsvc.MemberStatus {
+	members := entryMembersStatus(m.selectedEntry())
+	if m.memberCursor < 0 || m.memberCursor >= len(members) {
+		return nil
+	}
+	return members[m.memberCursor]
+}
+
+func (m *uiModel) clampCursors() {
+	clamp := func(v, length int) int {
+		if v >= length {
+			v = length - 1
+		}
+		if v < 0 {
+			v = 0
+		}
+		return v
+	}
+	m.cursor = clamp(m.cursor, len(m.visibleEntries()))
+	m.memberCursor = clamp(m.memberCursor, len(entryMembersStatus(m.selectedEntry())))
+}
+
+// HandleKey update state from a key, true is given back when entries must be refreshed now.
+func (m *uiModel) HandleKey(key string, pageSize int) bool {
+	if key == "ctrl-c" {
+		m.quit = true
+		return false
+	}
+	if m.input != uiInputNone {
+		return m.handleInputKey(key)
+	}
+	m.status = ""
+	move := func(delta int) {
+		if m.view == uiViewMembers {
+			m.memberCursor += delta
+		} else {
+			m.cursor += delta
+			m.memberCursor = 0
+		}
+		m.clampCursors()
+	}
+	switch key {
+	case "q":
+		m.quit = true
+	case "up", "k":
+		move(-1)
+	case "down", "j":
+		move(1)
+	case "pgup":
+		move(-pageSize)
+	case "pgdown":
+		move(pageSize)
+	case "r":
+		return true
+	case "enter", "right", "l":
+		if m.view == uiViewEntries && m.selectedEntry() != nil {
+			m.view = uiViewMembers
+			m.memberCursor = 0
+		}
+	case "esc", "left", "h", "backspace":
+		m.view = uiViewEntries
+	case "/":
+		m.input = uiInputFilter
+		m.inputBuffer = m.filter
+	case "t":
+		m.input = uiInputTags
+		m.inputBuffer = strings.Join(m.tags, ",")
+	case "e", "d":
+		m.askMemberStatus(key == "e")
+	}
+	return false
+}
+
+func (m *uiModel) handleInputKey(key string) bool {
+	if m.input == uiInputConfirm {
+		m.input = uiInputNone
+		if key != "y" && key != "Y" {
+			m.status = "Cancelled."
+			return false
+		}
+		err := m.onConfirm()
+		if err != nil {
+			m.status = msg.Red(err.Error()).String()
+			return false
+		}
+		return true
+	}
+	switch key {
+	case "esc":
+		m.input = uiInputNone
+	case "enter":
+		refresh := false
+		if m.input == uiInputFilter {
+			m.filter = strings.TrimSpace(m.inputBuffer)
+			m.view = uiViewEntries
+			m.cursor = 0
+		} else {
+			m.tags = splitTags(m.inputBuffer)
+			refresh = true
+		}
+		m.input = uiInputNone
+		m.clampCursors()
+		return refresh
+	case "backspace":
+		if m.inputBuffer != "" {
+			runes := []rune(m.inputBuffer)
+			m.inputBuffer = string(runes[:len(runes)-1])
+		}
+	default:
+		if len([]rune(key)) == 1 {
+			m.inputBuffer += key
+		}
+	}
+	return false
+}
+
+func splitTags(s string) []string {
+	tags := make([]string, 0)
+	for _, tag := range strings.Split(s, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+func (m *uiModel) askMemberStatus(enable bool) {
+	if m.view != uiViewMembers {
+		return
+	}
+	member := m.selectedMember()
+	if member == nil {
+		return
+	}
+	fqdn := m.selectedFqdn()
+	action := "Disable"
+	state := gslbsvc.MemberState_DISABLED
+	if enable {
+		action = "Enable"
+		state = gslbsvc.MemberState_ENABLED
+	}
+	m.input = uiInputConfirm
+	m.confirmText = fmt.Sprintf("%s member %s of %s? (y/N)", action, member.GetIp(), fqdn)
+	m.onConfirm = func() error {
+		err := m.setMemberStatus(fqdn, member.GetIp(), state)
+		if err != nil {
+			return err
+		}
+		m.status = fmt.Sprintf("%s done for member %s of %s, status is updated on next checks.", action, member.GetIp(), fqdn)
+		return nil
+	}
+}
+
+// setMemberStatus change status of a single member, a dry run is made first to ensure
+// that prefix does not match other entries.
+func (m *uiModel) setMemberStatus(fqdn, ip string, state gslbsvc.MemberState) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	makeReq := func(dryRun bool) *gslbsvc.SetMembersStatusRequest {
+		return &gslbsvc.SetMembersStatusRequest{
+			Prefix: fqdn,
+			Ip:     ip,
+			Status: state,
+			DryRun: dryRun,
+		}
+	}
+	resp, err := m.client.SetMembersStatus(ctx, makeReq(true))
+	if err != nil {
+		return err
+	}
+	for _, updated := range resp.GetUpdated() {
+		if updated.GetFqdn() != fqdn {
+			return fmt.Errorf("refusing to change status, entry %s would be changed as well", updated.GetFqdn())
+		}
+	}
+	_, err = m.client.SetMembersStatus(ctx, makeReq(false))
+	return err
+}
+
+// Render gives lines to draw on a screen of given height, lines wider than screen are expected to be cut by terminal.
+func (m *uiModel) Render(height int) []string {
+	lines := []string{m.headerLine()}
+	if m.refreshErr != nil {
+		lines = append(lines, msg.Red("Refresh failed: "+m.refreshErr.Error()).String())
+	} else {
+		lines = append(lines, "")
+	}
+
+	footer := []string{m.status, m.footerLine()}
+	bodyHeight := height - len(lines) - len(footer)
+	if bodyHeight < 2 {
+		bodyHeight = 2
+	}
+	var body []string
+	if m.view == uiViewMembers {
+		body = m.renderMembers(bodyHeight)
+	} else {
+		body = m.renderEntries(bodyHeight)
+	}
+	for len(body) < bodyHeight {
+		body = append(body, "")
+	}
+	lines = append(lines, body...)
+	return append(lines, footer...)
+}
+
+func (m *uiModel) headerLine() string {
+	header := fmt.Sprintf("gslocli ui | target: %s | entries: %d/%d",
+		m.target, len(m.visibleEntries()), len(m.entries))
+	if len(m.tags) > 0 {
+		header += " | tags: " + strings.Join(m.tags, ",")
+	}
+	if m.prefix != "" {
+		header += " | prefix: " + m.prefix
+	}
+	if m.filter != "" {
+		header += " | filter: " + m.filter
+	}
+	if !m.lastRefresh.IsZero() {
+		header += fmt.Sprintf(" | refreshed at %s (every %s)", m.lastRefresh.Format(time.TimeOnly), m.interval)
+	}
+	return msg.Cyan(header).String()
+}
+
+func (m *uiModel) footerLine() string {
+	switch m.input {
+	case uiInputFilter:
+		return "Filter fqdn: " + m.inputBuffer + "_"
+	case uiInputTags:
+		return "Tags (comma separated): " + m.inputBuffer + "_"
+	case uiInputConfirm:
+		return msg.Yellow(m.confirmText).String()
+	}
+	if m.view == uiViewMembers {
+		return "↑/↓ move | e enable | d disable | esc back | r refresh | q quit"
+	}
+	return "↑/↓ move | enter members | / filter | t tags | r refresh | q quit"
+}
+
+// scroll gives first line to show so cursor stays visible.
+func (m *uiModel) scroll(cursor, length, height int) int {
+	if cursor < m.offset {
+		m.offset = cursor
+	}
+	if cursor >= m.offset+height {
+		m.offset = cursor - height + 1
+	}
+	if m.offset > length-height {
+		m.offset = length - height
+	}
+	if m.offset < 0 {
+		m.offset = 0
+	}
+	return m.offset
+}
+
+func (m *uiModel) renderEntries(height int) []string {
+	ents := m.visibleEntries()
+	if len(ents) == 0 {
+		if m.lastRefresh.IsZero() {
+			return []string{"Loading..."}
+		}
+		return []string{"No entries found."}
+	}
+	fqdnWidth := len("FQDN")
+	for _, ent := range ents {
+		if len(ent.GetFqdn()) > fqdnWidth {
+			fqdnWidth = len(ent.GetFqdn())
+		}
+	}
+	dcWidths := make([]int, len(m.dcs))
+	header := fmt.Sprintf("  %-*s", fqdnWidth, "FQDN")
+	for i, dc := range m.dcs {
+		dcWidths[i] = len(dc)
+		if dcWidths[i] < 7 {
+			dcWidths[i] = 7
+		}
+		header += fmt.Sprintf("  %-*s", dcWidths[i], dc)
+	}
+	lines := []string{header}
+
+	start := m.scroll(m.cursor, len(ents), height-1)
+	for i := start; i < len(ents) && i < start+height-1; i++ {
+		ent := ents[i]
+		selected := i == m.cursor
+		line := fmt.Sprintf("  %-*s", fqdnWidth, ent.GetFqdn())
+		if selected {
+			line = fmt.Sprintf("> %-*s", fqdnWidth, ent.GetFqdn())
+		}
+		for j, dc := range m.dcs {
+			line += "  " + m.dcCell(ent, dc, dcWidths[j], !selected)
+		}
+		if selected {
+			line = ansiReverse + line + ansiReset
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// dcCell gives online members count on members count in a datacenter, colored by health when colored is true.
+func (m *uiModel) dcCell(ent *gslbsvc.GetEntryStatusResponse, dc string, width int, colored bool) string {
+	nbOnline, nbMembers, changed := 0, 0, false
+	for _, member := range entryMembersStatus(ent) {
+		if member.GetDc() != dc {
+			continue
+		}
+		nbMembers++
+		if member.GetStatus() == gslbsvc.MemberStatus_ONLINE {
+			nbOnline++
+		}
+		if _, ok := m.transitions[MemberStatusKey(ent.GetFqdn(), member.GetIp())]; ok {
+			changed = true
+		}
+	}
+	text := "-"
+	if nbMembers > 0 {
+		text = fmt.Sprintf("%d/%d", nbOnline, nbMembers)
+	}
+	if changed {
+		text += "*"
+	}
+	text = fmt.Sprintf("%-*s", width, text)
+	if !colored || nbMembers == 0 {
+		return text
+	}
+	switch {
+	case changed:
+		return msg.Magenta(text).String()
+	case nbOnline == nbMembers:
+		return msg.Green(text).String()
+	case nbOnline == 0:
+		return msg.Red(text).String()
+	}
+	return msg.Yellow(text).String()
+}
+
+func (m *uiModel) renderMembers(height int) []string {
+	ent := m.selectedEntry()
+	if ent == nil {
+		m.view = uiViewEntries
+		return m.renderEntries(height)
+	}
+	members := entryMembersStatus(ent)
+	lines := []string{
+		"Members of " + msg.Cyan(ent.GetFqdn()).String(),
+		fmt.Sprintf("  %-10s  %-39s  %s", "DC", "IP", "STATUS"),
+	}
+	start := m.scroll(m.memberCursor, len(members), height-2)
+	for i := start; i < len(members) && i < start+height-2; i++ {
+		member := members[i]
+		status := MemberStatusLabel(member)
+		if member.GetFailureReason() != "" && !IsDisabledByUser(member) {
+			status += ": " + member.GetFailureReason()
+		}
+		prefix := "  "
+		if i == m.memberCursor {
+			prefix = "> "
+		}
+		line := fmt.Sprintf("%s%-10s  %-39s  ", prefix, member.GetDc(), member.GetIp())
+		if i == m.memberCursor {
+			lines = append(lines, ansiReverse+line+status+ansiReset+TransitionText(m.transitions, ent.GetFqdn(), member.GetIp()))
+			continue
+		}
+		lines = append(lines, line+colorStatus(member, status)+TransitionText(m.transitions, ent.GetFqdn(), member.GetIp()))
+	}
+	return lines
+}
+
+func colorStatus(member *gslbsvc.MemberStatus, text string) string {
+	switch {
+	case member.GetStatus() == gslbsvc.MemberStatus_ONLINE:
+		return msg.Green(text).String()
+	case IsDisabledByUser(member):
+		return msg.Yellow(text).String()
+	}
+	return msg.Red(text).String()
+}
@@ -0,0 +1,83 @@
+package cli
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	gslbsvc "github.com/orange-cloudfoundry/gsloc-go-sdk/gsloc/services/gslb/v1"
+)
+
+func TestParseKeys(t *testing.T) {
+	keys := ParseKeys([]byte("j\x1b[A\x1b[6~\x1b\r/é\x7f\x03"))
+	expected := []string{"j", "up", "pgdown", "esc", "enter", "/", "é", "backspace", "ctrl-c"}
+	if !reflect.DeepEqual(keys, expected) {
+		t.Errorf("Expected %v but got %v", expected, keys)
+	}
+}
+
+func TestUiModel(t *testing.T) {
+	client := &fakeClient{
+		setMembersStatusResp: &gslbsvc.SetMembersStatusResponse{
+			Updated: []*gslbsvc.SetMembersStatusResponse_Info{
+				{Fqdn: "b.example.com.", Ips: []string{"10.0.1.2"}},
+			},
+		},
+	}
+	model := &uiModel{
+		client: client,
+		dcs:    []string{"dc1", "dc2"},
+	}
+	model.SetEntries([]*gslbsvc.GetEntryStatusResponse{
+		{
+			Fqdn: "b.example.com.",
+			MembersIpv4: []*gslbsvc.MemberStatus{
+				{Ip: "10.0.1.1", Dc: "dc1", Status: gslbsvc.MemberStatus_ONLINE},
+				{Ip: "10.0.1.2", Dc: "dc2", Status: gslbsvc.MemberStatus_ONLINE},
+			},
+		},
+		{
+			Fqdn: "a.example.com.",
+			MembersIpv4: []*gslbsvc.MemberStatus{
+				{Ip: "10.0.0.1", Dc: "dc1", Status: gslbsvc.MemberStatus_OFFLINE},
+			},
+		},
+	}, time.Now())
+
+	lines := strings.Join(model.Render(20), "\n")
+	if !strings.Contains(lines, "entries: 2/2") || !strings.Contains(lines, "1/1") || !strings.Contains(lines, "0/1") {
+		t.Errorf("Unexpected entries view:\n%s", lines)
+	}
+
+	for _, key := range []string{"/", "b", ".", "enter"} {
+		model.HandleKey(key, 10)
+	}
+	if len(model.visibleEntries()) != 1 || model.selectedFqdn() != "b.example.com." {
+		t.Fatalf("Expected only b.example.com. after filtering but got %d entries", len(model.visibleEntries()))
+	}
+
+	for _, key := range []string{"enter", "down", "d"} {
+		model.HandleKey(key, 10)
+	}
+	if !strings.Contains(model.footerLine(), "Disable member 10.0.1.2 of b.example.com.?") {
+		t.Errorf("Expected confirmation but got %q", model.footerLine())
+	}
+	if !model.HandleKey("y", 10) {
+		t.Errorf("Expected a refresh after member status change")
+	}
+	if len(client.setMembersStatusReqs) != 2 || !client.setMembersStatusReqs[0].DryRun || client.setMembersStatusReqs[1].DryRun {
+		t.Fatalf("Expected a dry run followed by a real call, got %v", client.setMembersStatusReqs)
+	}
+	req := client.setMembersStatusReqs[1]
+	if req.Prefix != "b.example.com." || req.Ip != "10.0.1.2" || req.Status != gslbsvc.MemberState_DISABLED {
+		t.Errorf("Unexpected request %v", req)
+	}
+
+	client.setMembersStatusResp.Updated = append(client.setMembersStatusResp.Updated,
+		&gslbsvc.SetMembersStatusResponse_Info{Fqdn: "b.example.com.other.", Ips: []string{"10.0.1.2"}})
+	model.HandleKey("e", 10)
+	if model.HandleKey("y", 10) || len(client.setMembersStatusReqs) != 3 {
+		t.Errorf("Expected status change to be refused when other entries match")
+	}
+}
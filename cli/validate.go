@@ -0,0 +1,375 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/ArthurHlt/go-flags"
+	msg "github.com/ArthurHlt/messages"
+	"github.com/orange-cloudfoundry/gsloc-cli/app"
+	"github.com/orange-cloudfoundry/gsloc-go-sdk/gsloc/api/config/entries/v1"
+	gslbsvc "github.com/orange-cloudfoundry/gsloc-go-sdk/gsloc/services/gslb/v1"
+	"github.com/orange-cloudfoundry/gsloc-go-sdk/helpers"
+	"gopkg.in/yaml.v3"
+	"net"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+var (
+	fqdnLabelRegex  = regexp.MustCompile(`^(?i)[a-z0-9_]([a-z0-9_-]{0,61}[a-z0-9_])?$`)
+	fieldIndexRegex = regexp.MustCompile(`^(.+)\[(\d+)\]$`)
+)
+
+// ManifestProblem is an error found in a manifest, Line is the line in file.
+type ManifestProblem struct {
+	File    string `json:"file"`
+	Line    int    `json:"line"`
+	Path    string `json:"path,omitempty"`
+	Message string `json:"message"`
+}
+
+func (p *ManifestProblem) String() string {
+	if p.Path == "" {
+		return fmt.Sprintf("%s:%d: %s", p.File, p.Line, p.Message)
+	}
+	return fmt.Sprintf("%s:%d: %s: %s", p.File, p.Line, p.Path, p.Message)
+}
+
+// entryProblem is an error on a field of a set entry request, path is made of yaml keys and list indexes.
+type entryProblem struct {
+	path    []any
+	message string
+}
+
+type Validate struct {
+	File flags.Filename `short:"f" long:"file" description:"Path to a json or yml file or to a directory of files with entries definitions (multi-document yml are supported)" required:"true"`
+	Live bool           `long:"live" description:"Also check manifests against current target, e.g. datacenters of members must exist"`
+
+	OutputFormat
+}
+
+var validate Validate
+
+func (c *Validate) Execute([]string) error {
+	files, err := ManifestFiles(string(c.File))
+	if err != nil {
+		return err
+	}
+	var dcs []string
+	if c.Live {
+		dcs, err = c.liveDcs()
+		if err != nil {
+			return err
+		}
+	}
+
+	problems := make([]*ManifestProblem, 0)
+	nbManifests := 0
+	for _, file := range files {
+		content, err := os.ReadFile(file)
+		if err != nil {
+			return fmt.Errorf("failed to read file %s: %w", file, err)
+		}
+		docs := [][]byte{content}
+		lines := []int{1}
+		if IsYamlFile(file) {
+			docs, lines = SplitYamlDocuments(content)
+		}
+		for i, doc := range docs {
+			docProblems, loaded := ValidateManifest(file, doc, lines[i], dcs)
+			if loaded {
+				nbManifests++
+			}
+			problems = append(problems, docProblems...)
+		}
+	}
+
+	names := make([]string, len(problems))
+	for i, problem := range problems {
+		names[i] = problem.String()
+	}
+	err = c.Print(problems, names, func(bool) error {
+		for _, problem := range problems {
+			msg.Error(problem.String())
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("%d problems found in %d manifests", len(problems), nbManifests)
+	}
+	if c.IsHuman() {
+		msg.Successf("%d manifests are valid.", nbManifests)
+	}
+	return nil
+}
+
+// liveDcs gives datacenters of current target, this command does not need a target when not live.
+func (c *Validate) liveDcs() ([]string, error) {
+	clientConn, err := app.CreateConnFromFile(ExpandConfigPath(), opts.Target)
+	if err != nil {
+		return nil, err
+	}
+	defer clientConn.Close() // nolint:errcheck
+	resp, err := app.MakeClient(clientConn).ListDcs(context.Background(), &gslbsvc.ListDcsRequest{})
+	if err != nil {
+		return nil, err
+	}
+	return resp.GetDcs(), nil
+}
+
+// ValidateManifest check a manifest document starting at startLine in file with validation rules of the sdk
+// and semantic checks, datacenters of members are checked only when dcs is not nil.
+// Loaded is false when document is empty.
+func ValidateManifest(file string, content []byte, startLine int, dcs []string) (problems []*ManifestProblem, loaded bool) {
+	setEntryReq, loaded, err := BytesToProto[*gslbsvc.SetEntryRequest](content, file)
+	if err != nil {
+		return []*ManifestProblem{{File: file, Line: startLine, Message: err.Error()}}, true
+	}
+	if !loaded {
+		return nil, false
+	}
+
+	entProblems := validationProblems(helpers.Validate(setEntryReq), nil)
+	entProblems = append(entProblems, EntryProblems(setEntryReq, dcs)...)
+
+	root := &yaml.Node{}
+	if yaml.Unmarshal(content, root) != nil {
+		root = nil
+	}
+	problems = make([]*ManifestProblem, 0, len(entProblems))
+	for _, entProblem := range entProblems {
+		problems = append(problems, &ManifestProblem{
+			File:    file,
+			Line:    startLine + yamlPathLine(root, entProblem.path) - 1,
+			Path:    formatProblemPath(entProblem.path),
+			Message: entProblem.message,
+		})
+	}
+	return problems, true
+}
+
+// EntryProblems gives errors that validation rules of the sdk can't find.
+func EntryProblems(setEntryReq *gslbsvc.SetEntryRequest, dcs []string) []*entryProblem {
+	problems := make([]*entryProblem, 0)
+	ent := setEntryReq.GetEntry()
+	if ent.GetFqdn() != "" && !IsValidFqdn(ent.GetFqdn()) {
+		problems = append(problems, &entryProblem{
+			path:    []any{"entry", "fqdn"},
+			message: fmt.Sprintf("%s is not a valid fqdn", ent.GetFqdn()),
+		})
+	}
+
+	knownDcs := make(map[string]bool)
+	for _, dc := range dcs {
+		knownDcs[dc] = true
+	}
+	seenIps := make(map[string]string)
+	hasDc := false
+	for _, list := range []struct {
+		key     string
+		members []*entries.Member
+		ipv4    bool
+	}{
+		{"members_ipv4", ent.GetMembersIpv4(), true},
+		{"members_ipv6", ent.GetMembersIpv6(), false},
+	} {
+		for i, member := range list.members {
+			if member.GetDc() != "" {
+				hasDc = true
+			}
+			if dcs != nil && member.GetDc() != "" && !knownDcs[member.GetDc()] {
+				problems = append(problems, &entryProblem{
+					path:    []any{"entry", list.key, i, "dc"},
+					message: fmt.Sprintf("datacenter %s does not exist on target", member.GetDc()),
+				})
+			}
+			ip := net.ParseIP(member.GetIp())
+			if ip == nil {
+				continue
+			}
+			if isIpv4 := ip.To4() != nil; isIpv4 != list.ipv4 {
+				problems = append(problems, &entryProblem{
+					path:    []any{"entry", list.key, i, "ip"},
+					message: fmt.Sprintf("%s is not an ip%s address", member.GetIp(), strings.TrimPrefix(list.key, "members_ip")),
+				})
+			}
+			if prevKey, ok := seenIps[ip.String()]; ok {
+				problems = append(problems, &entryProblem{
+					path:    []any{"entry", list.key, i, "ip"},
+					message: fmt.Sprintf("%s is already a member in %s", member.GetIp(), prevKey),
+				})
+				continue
+			}
+			seenIps[ip.String()] = fmt.Sprintf("%s[%d]", list.key, i)
+		}
+	}
+
+	for _, algo := range []struct {
+		key  string
+		algo entries.LBAlgo
+	}{
+		{"lb_algo_preferred", ent.GetLbAlgoPreferred()},
+		{"lb_algo_alternate", ent.GetLbAlgoAlternate()},
+		{"lb_algo_fallback", ent.GetLbAlgoFallback()},
+	} {
+		if algo.algo == entries.LBAlgo_TOPOLOGY && !hasDc {
+			problems = append(problems, &entryProblem{
+				path:    []any{"entry", algo.key},
+				message: "TOPOLOGY needs members with a datacenter",
+			})
+		}
+	}
+
+	hc := setEntryReq.GetHealthcheck()
+	if rnge := hc.GetHttpHealthCheck().GetExpectedStatuses(); rnge.GetStart() > rnge.GetEnd() {
+		problems = append(problems, &entryProblem{
+			path:    []any{"healthcheck", "http_health_check", "expected_statuses"},
+			message: fmt.Sprintf("start %d is greater than end %d", rnge.GetStart(), rnge.GetEnd()),
+		})
+	}
+	if hc.GetTimeout() != nil && hc.GetInterval() != nil && hc.GetInterval().AsDuration() < hc.GetTimeout().AsDuration() {
+		problems = append(problems, &entryProblem{
+			path:    []any{"healthcheck", "interval"},
+			message: fmt.Sprintf("interval %s is shorter than timeout %s", hc.GetInterval().AsDuration(), hc.GetTimeout().AsDuration()),
+		})
+	}
+	return problems
+}
+
+// IsValidFqdn checks that each label of a domain name is made of letters, digits, '-' or '_' and is not too long.
+func IsValidFqdn(fqdn string) bool {
+	fqdn = strings.TrimSuffix(fqdn, ".")
+	if fqdn == "" || len(fqdn) > 253 {
+		return false
+	}
+	for _, label := range strings.Split(fqdn, ".") {
+		if !fqdnLabelRegex.MatchString(label) {
+			return false
+		}
+	}
+	return true
+}
+
+// validationProblems flatten errors from validation rules of the sdk,
+// nested messages errors are followed to give the path of the invalid field.
+func validationProblems(err error, path []any) []*entryProblem {
+	if err == nil {
+		return nil
+	}
+	var multiErr interface{ AllErrors() []error }
+	if errors.As(err, &multiErr) {
+		problems := make([]*entryProblem, 0)
+		for _, subErr := range multiErr.AllErrors() {
+			problems = append(problems, validationProblems(subErr, path)...)
+		}
+		return problems
+	}
+	var fieldErr interface {
+		Field() string
+		Reason() string
+		Cause() error
+	}
+	if !errors.As(err, &fieldErr) {
+		return []*entryProblem{{path: path, message: err.Error()}}
+	}
+	fieldPath := append(append([]any{}, path...), fieldToYamlPath(fieldErr.Field())...)
+	if fieldErr.Cause() != nil {
+		return validationProblems(fieldErr.Cause(), fieldPath)
+	}
+	return []*entryProblem{{path: fieldPath, message: fieldErr.Reason()}}
+}
+
+// fieldToYamlPath convert a field from validation rules (e.g.: MembersIpv4[0]) to yaml keys (e.g.: members_ipv4, 0).
+func fieldToYamlPath(field string) []any {
+	if match := fieldIndexRegex.FindStringSubmatch(field); match != nil {
+		index, _ := strconv.Atoi(match[2])
+		return []any{camelToSnake(match[1]), index}
+	}
+	return []any{camelToSnake(field)}
+}
+
+func camelToSnake(s string) string {
+	buf := &strings.Builder{}
+	runes := []rune(s)
+	for i, r := range runes {
+		if unicode.IsUpper(r) {
+			if i > 0 && (unicode.IsLower(runes[i-1]) || unicode.IsDigit(runes[i-1])) {
+				buf.WriteRune('_')
+			}
+			r = unicode.ToLower(r)
+		}
+		buf.WriteRune(r)
+	}
+	return buf.String()
+}
+
+func formatProblemPath(path []any) string {
+	buf := &strings.Builder{}
+	for _, elem := range path {
+		switch v := elem.(type) {
+		case int:
+			fmt.Fprintf(buf, "[%d]", v)
+		default:
+			if buf.Len() > 0 {
+				buf.WriteByte('.')
+			}
+			fmt.Fprint(buf, v)
+		}
+	}
+	return buf.String()
+}
+
+// yamlPathLine gives line in document of the deepest existing node in path, json documents are supported as well.
+func yamlPathLine(root *yaml.Node, path []any) int {
+	if root == nil || len(root.Content) == 0 {
+		return 1
+	}
+	node := root.Content[0]
+	line := node.Line
+	for _, elem := range path {
+		var next *yaml.Node
+		switch v := elem.(type) {
+		case int:
+			if node.Kind == yaml.SequenceNode && v < len(node.Content) {
+				next = node.Content[v]
+				line = next.Line
+			}
+		case string:
+			if node.Kind == yaml.MappingNode {
+				for i := 0; i+1 < len(node.Content); i += 2 {
+					if node.Content[i].Value == v {
+						// key line is used as value can be on next lines
+						next = node.Content[i+1]
+						line = node.Content[i].Line
+						break
+					}
+				}
+			}
+		}
+		if next == nil {
+			break
+		}
+		node = next
+	}
+	return line
+}
+
+func init() {
+	desc := "Validate entries definitions without applying them, works offline unless --live is set."
+	cmd, err := parser.AddCommand(
+		"validate",
+		desc,
+		desc,
+		&validate)
+	if err != nil {
+		panic(err)
+	}
+	cmd.Aliases = []string{"lint"}
+}
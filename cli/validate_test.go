@@ -0,0 +1,67 @@
+package cli
+
+import (
+	"testing"
+)
+
+func TestValidateManifest(t *testing.T) {
+	content := []byte(`entry:
+  fqdn: a.example.com
+  lb_algo_preferred: TOPOLOGY
+  members_ipv4:
+  - ip: 10.0.0.1
+    dc: dc1
+  - ip: 2001:db8::1
+    dc: dc3
+  members_ipv6:
+  - ip: 10.0.0.1
+    dc: dc1
+  - ip: not-an-ip
+    dc: dc1
+healthcheck:
+  timeout: 10s
+  interval: 5s
+  port: 80
+  http_health_check:
+    path: /
+    expected_statuses:
+      start: 300
+      end: 200
+`)
+	problems, loaded := ValidateManifest("entries.yml", content, 5, []string{"dc1", "dc2"})
+	if !loaded {
+		t.Fatal("Expected manifest to be loaded")
+	}
+	expected := map[string]bool{
+		"entries.yml:16: entry.members_ipv6[1].ip: value must be a valid IP address":                         true,
+		"entries.yml:11: entry.members_ipv4[1].ip: 2001:db8::1 is not an ipv4 address":                       true,
+		"entries.yml:12: entry.members_ipv4[1].dc: datacenter dc3 does not exist on target":                  true,
+		"entries.yml:14: entry.members_ipv6[0].ip: 10.0.0.1 is not an ipv6 address":                          true,
+		"entries.yml:14: entry.members_ipv6[0].ip: 10.0.0.1 is already a member in members_ipv4[0]":          true,
+		"entries.yml:24: healthcheck.http_health_check.expected_statuses: start 300 is greater than end 200": true,
+		"entries.yml:20: healthcheck.interval: interval 5s is shorter than timeout 10s":                      true,
+	}
+	for _, problem := range problems {
+		if !expected[problem.String()] {
+			t.Errorf("Unexpected problem %q", problem.String())
+		}
+		delete(expected, problem.String())
+	}
+	for problem := range expected {
+		t.Errorf("Expected problem %q", problem)
+	}
+
+	problems, _ = ValidateManifest("entries.json", []byte(`{
+  "entry": {"fqdn": "b..example.com", "lb_algo_preferred": "TOPOLOGY"},
+  "healthcheck": {"timeout": "1s", "interval": "1s", "port": 80, "no_health_check": {}}
+}`), 1, nil)
+	if len(problems) != 2 || problems[0].String() != "entries.json:2: entry.fqdn: b..example.com is not a valid fqdn" ||
+		problems[1].String() != "entries.json:2: entry.lb_algo_preferred: TOPOLOGY needs members with a datacenter" {
+		t.Errorf("Unexpected problems %v", problems)
+	}
+
+	problems, _ = ValidateManifest("entries.yml", []byte("entry:\n  unknown: 1\n"), 1, nil)
+	if len(problems) != 1 || problems[0].Line != 1 {
+		t.Errorf("Expected syntax problem but got %v", problems)
+	}
+}
@@ -0,0 +1,209 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	msg "github.com/ArthurHlt/messages"
+	gslbsvc "github.com/orange-cloudfoundry/gsloc-go-sdk/gsloc/services/gslb/v1"
+	"os"
+	"os/signal"
+	"time"
+)
+
+// WaitTimeoutExitCode is the exit code when members did not reach expected state in time, same as timeout(1).
+const WaitTimeoutExitCode = 124
+
+const (
+	WaitForOnline   = "online"
+	WaitForOffline  = "offline"
+	WaitForDisabled = "disabled"
+)
+
+type Wait struct {
+	FQDN *FQDN `positional-args:"true" positional-arg-name:"'fqdn'"`
+
+	Ip     string   `short:"i" long:"ip" description:"Only wait for the member with this IP."`
+	DC     string   `short:"d" long:"dc" description:"Only wait for members in this datacenter."`
+	Tags   []string `short:"t" long:"tag" description:"Wait for members of entries with tag(s) when no fqdn is given (can be set multiple times)."`
+	Prefix string   `short:"p" long:"prefix" description:"Wait for members of entries with prefix when no fqdn is given."`
+
+	For         string        `long:"for" description:"State to reach, offline means any status other than online and disabled means disabled by user." choice:"online" choice:"offline" choice:"disabled" required:"true"`
+	Timeout     time.Duration `long:"timeout" description:"Maximum time to wait, exit with code 124 when reached." default:"5m"`
+	Interval    time.Duration `short:"n" long:"interval" description:"First interval between two polls, it is doubled after each poll up to max interval." default:"1s"`
+	MaxInterval time.Duration `long:"max-interval" description:"Maximum interval between two polls." default:"30s"`
+
+	client gslbsvc.GSLBClient
+}
+
+type WaitMember struct {
+	Fqdn   string
+	Status *gslbsvc.MemberStatus
+}
+
+var wait Wait
+
+func (c *Wait) SetClient(client gslbsvc.GSLBClient) {
+	c.client = client
+}
+
+func (c *Wait) Execute([]string) error {
+	if c.FQDN.IsEmpty() && c.Prefix == "" && len(c.Tags) == 0 && c.Ip == "" && c.DC == "" {
+		return fmt.Errorf("a fqdn or one of --prefix, --tag, --ip or --dc must be set")
+	}
+	if c.Interval <= 0 {
+		return fmt.Errorf("interval must be positive")
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), c.Timeout)
+	defer cancel()
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt)
+	defer stop()
+
+	interval := c.Interval
+	nbReady := -1
+	var pending []*WaitMember
+	var lastErr error
+	noMatch := false
+	for {
+		members, err := c.fetch(ctx)
+		switch {
+		case err == nil && len(members) == 0:
+			// members may not be created yet, e.g. right after set-entry
+			if !noMatch {
+				msg.Info("No members match given selectors yet, waiting for them.")
+			}
+			noMatch = true
+			nbReady = -1
+			pending = []*WaitMember{}
+		case err == nil:
+			noMatch = false
+			pending = PendingMembers(members, c.For)
+			if ready := len(members) - len(pending); ready != nbReady {
+				nbReady = ready
+				msg.Infof("%d/%d members %s.", ready, len(members), c.For)
+			}
+			if len(pending) == 0 {
+				msg.Successf("All members are %s.", c.For)
+				return nil
+			}
+		case ctx.Err() == nil:
+			lastErr = err
+			msg.Warning(fmt.Sprintf("Failed to get status, retrying: %s", err.Error()))
+		}
+
+		select {
+		case <-ctx.Done():
+			return c.stopError(ctx, pending, lastErr)
+		case <-time.After(interval):
+		}
+		interval *= 2
+		if interval > c.MaxInterval {
+			interval = c.MaxInterval
+		}
+	}
+}
+
+func (c *Wait) fetch(ctx context.Context) ([]*WaitMember, error) {
+	var ents []*gslbsvc.GetEntryStatusResponse
+	if !c.FQDN.IsEmpty() {
+		entResp, err := c.client.GetEntryStatus(ctx, &gslbsvc.GetEntryStatusRequest{
+			Fqdn: c.FQDN.String(),
+		})
+		if err != nil {
+			return nil, err
+		}
+		ents = append(ents, entResp)
+	} else {
+		entsResp, err := c.client.ListEntriesStatus(ctx, &gslbsvc.ListEntriesStatusRequest{
+			Tags:   c.Tags,
+			Prefix: c.Prefix,
+		})
+		if err != nil {
+			return nil, err
+		}
+		ents = entsResp.GetEntriesStatus()
+	}
+
+	members := make([]*WaitMember, 0)
+	for _, ent := range ents {
+		for _, member := range entryMembersStatus(ent) {
+			if (c.Ip != "" && member.GetIp() != c.Ip) || (c.DC != "" && member.GetDc() != c.DC) {
+				continue
+			}
+			members = append(members, &WaitMember{
+				Fqdn:   ent.GetFqdn(),
+				Status: member,
+			})
+		}
+	}
+	return members, nil
+}
+
+func (c *Wait) stopError(ctx context.Context, pending []*WaitMember, lastErr error) error {
+	if !errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return fmt.Errorf("interrupted")
+	}
+	if pending == nil {
+		if lastErr != nil {
+			return &ExitError{
+				Code:    WaitTimeoutExitCode,
+				Message: fmt.Sprintf("Timeout after %s, status could not be retrieved: %s", c.Timeout, lastErr.Error()),
+			}
+		}
+		return &ExitError{
+			Code:    WaitTimeoutExitCode,
+			Message: fmt.Sprintf("Timeout after %s, status could not be retrieved.", c.Timeout),
+		}
+	}
+	if len(pending) == 0 {
+		return &ExitError{
+			Code:    WaitTimeoutExitCode,
+			Message: fmt.Sprintf("Timeout after %s, no members match given selectors.", c.Timeout),
+		}
+	}
+	msg.Warning(fmt.Sprintf("Members not %s:", c.For))
+	table := MakeTableWriter([]string{"FQDN", "DC", "Member"})
+	table.SetAutoWrapText(false)
+	for _, member := range pending {
+		table.Append([]string{member.Fqdn, member.Status.GetDc(), MemberStatusText(member.Status)})
+	}
+	table.Render()
+	return &ExitError{
+		Code:    WaitTimeoutExitCode,
+		Message: fmt.Sprintf("Timeout after %s, %d members are not %s.", c.Timeout, len(pending), c.For),
+	}
+}
+
+// PendingMembers gives members which have not reached expected state yet.
+func PendingMembers(members []*WaitMember, waitFor string) []*WaitMember {
+	pending := make([]*WaitMember, 0)
+	for _, member := range members {
+		online := member.Status.GetStatus() == gslbsvc.MemberStatus_ONLINE
+		var reached bool
+		switch waitFor {
+		case WaitForOnline:
+			reached = online
+		case WaitForOffline:
+			reached = !online
+		case WaitForDisabled:
+			reached = IsDisabledByUser(member.Status)
+		}
+		if !reached {
+			pending = append(pending, member)
+		}
+	}
+	return pending
+}
+
+func init() {
+	desc := "Wait until members reach a state, exit with code 124 on timeout."
+	cmd, err := parser.AddCommand(
+		"wait",
+		desc,
+		desc,
+		&wait)
+	if err != nil {
+		panic(err)
+	}
+	cmd.Aliases = []string{"w"}
+}
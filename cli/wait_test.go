@@ -0,0 +1,90 @@
+package cli
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	gslbsvc "github.com/orange-cloudfoundry/gsloc-go-sdk/gsloc/services/gslb/v1"
+)
+
+func TestWait(t *testing.T) {
+	entStatus := func(status gslbsvc.MemberStatus_Status) []*gslbsvc.GetEntryStatusResponse {
+		return []*gslbsvc.GetEntryStatusResponse{{
+			Fqdn: "a.example.com.",
+			MembersIpv4: []*gslbsvc.MemberStatus{
+				{Ip: "10.0.0.1", Dc: "dc1", Status: gslbsvc.MemberStatus_ONLINE},
+				{Ip: "10.0.0.2", Dc: "dc2", Status: status},
+			},
+		}}
+	}
+	client := &fakeClient{
+		entriesStatus: [][]*gslbsvc.GetEntryStatusResponse{
+			entStatus(gslbsvc.MemberStatus_ONLINE),
+			entStatus(gslbsvc.MemberStatus_ONLINE),
+			entStatus(gslbsvc.MemberStatus_OFFLINE),
+		},
+	}
+	cmd := &Wait{
+		Prefix:      "a.example",
+		DC:          "dc2",
+		For:         WaitForOffline,
+		Timeout:     time.Second,
+		Interval:    time.Millisecond,
+		MaxInterval: time.Millisecond,
+		client:      client,
+	}
+	err := cmd.Execute(nil)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if client.nbCalls != 3 {
+		t.Errorf("Expected 3 polls but got %d", client.nbCalls)
+	}
+
+	cmd.DC = ""
+	cmd.Timeout = 20 * time.Millisecond
+	err = cmd.Execute(nil)
+	var exitErr *ExitError
+	if !errors.As(err, &exitErr) || exitErr.Code != WaitTimeoutExitCode {
+		t.Fatalf("Expected timeout exit error but got %v", err)
+	}
+}
+
+func TestWaitMembersNotCreatedYet(t *testing.T) {
+	noMember := []*gslbsvc.GetEntryStatusResponse{{Fqdn: "a.example.com."}}
+	client := &fakeClient{
+		entriesStatus: [][]*gslbsvc.GetEntryStatusResponse{
+			noMember,
+			noMember,
+			{{
+				Fqdn:        "a.example.com.",
+				MembersIpv4: []*gslbsvc.MemberStatus{{Ip: "10.0.0.1", Dc: "dc1", Status: gslbsvc.MemberStatus_ONLINE}},
+			}},
+		},
+	}
+	cmd := &Wait{
+		Prefix:      "a.example",
+		For:         WaitForOnline,
+		Timeout:     time.Second,
+		Interval:    time.Millisecond,
+		MaxInterval: time.Millisecond,
+		client:      client,
+	}
+	err := cmd.Execute(nil)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if client.nbCalls != 3 {
+		t.Errorf("Expected 3 polls but got %d", client.nbCalls)
+	}
+
+	client.entriesStatus = [][]*gslbsvc.GetEntryStatusResponse{noMember}
+	cmd.Timeout = 20 * time.Millisecond
+	err = cmd.Execute(nil)
+	var exitErr *ExitError
+	if !errors.As(err, &exitErr) || exitErr.Code != WaitTimeoutExitCode || !strings.Contains(exitErr.Message, "no members match") {
+		t.Fatalf("Expected timeout exit error with no members matching but got %v", err)
+	}
+}
@@ -0,0 +1,262 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	msg "github.com/ArthurHlt/messages"
+	gslbsvc "github.com/orange-cloudfoundry/gsloc-go-sdk/gsloc/services/gslb/v1"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+)
+
+const (
+	UntilAllOnline  = "all-online"
+	UntilAllOffline = "all-offline"
+
+	StatusDisabled = "DISABLED"
+
+	clearScreen = "\033[H\033[2J"
+)
+
+// WatchOptions must be embedded in commands showing entries status to give them a watch mode.
+type WatchOptions struct {
+	Watch    bool          `short:"w" long:"watch" description:"Poll status until interrupted, table is redrawn in place or one line per change is printed when not in a terminal."`
+	Interval time.Duration `short:"n" long:"interval" description:"Interval between two polls in watch mode." default:"5s"`
+	Until    string        `long:"until" description:"Stop watching when condition holds, members disabled by user are ignored." choice:"all-online" choice:"all-offline"`
+}
+
+// StatusEvent is a member status change between two polls, From is empty for a member seen
+// for the first time and To is empty for a member which disappeared.
+type StatusEvent struct {
+	Time   time.Time `json:"time"`
+	Fqdn   string    `json:"fqdn"`
+	Ip     string    `json:"ip"`
+	Dc     string    `json:"dc"`
+	From   string    `json:"from"`
+	To     string    `json:"to"`
+	Reason string    `json:"reason,omitempty"`
+}
+
+func (e *StatusEvent) String() string {
+	text := fmt.Sprintf("%s %s %s (%s) ", e.Time.Format(time.RFC3339), e.Fqdn, e.Ip, e.Dc)
+	switch {
+	case e.From == "":
+		text += e.To
+	case e.To == "":
+		text += e.From + " -> REMOVED"
+	default:
+		text += e.From + " -> " + e.To
+	}
+	if e.Reason != "" {
+		text += ": " + e.Reason
+	}
+	return text
+}
+
+// MemberStatusLabel gives member status name, members disabled by user are seen as DISABLED instead of CHECK_FAILED.
+func MemberStatusLabel(status *gslbsvc.MemberStatus) string {
+	if IsDisabledByUser(status) {
+		return StatusDisabled
+	}
+	return status.GetStatus().String()
+}
+
+func IsDisabledByUser(status *gslbsvc.MemberStatus) bool {
+	return status.GetStatus() == gslbsvc.MemberStatus_CHECK_FAILED &&
+		strings.Contains(status.GetFailureReason(), "disabled entry")
+}
+
+func MemberStatusKey(fqdn, ip string) string {
+	return fqdn + " " + ip
+}
+
+func entryMembersStatus(ent *gslbsvc.GetEntryStatusResponse) []*gslbsvc.MemberStatus {
+	members := make([]*gslbsvc.MemberStatus, 0, len(ent.GetMembersIpv4())+len(ent.GetMembersIpv6()))
+	members = append(members, ent.GetMembersIpv4()...)
+	return append(members, ent.GetMembersIpv6()...)
+}
+
+// StatusEvents gives members status changes from previous poll to current one.
+func StatusEvents(previous, current []*gslbsvc.GetEntryStatusResponse, now time.Time) []*StatusEvent {
+	previousMembers := make(map[string]*gslbsvc.MemberStatus)
+	for _, ent := range previous {
+		for _, member := range entryMembersStatus(ent) {
+			previousMembers[MemberStatusKey(ent.GetFqdn(), member.GetIp())] = member
+		}
+	}
+	events := make([]*StatusEvent, 0)
+	seen := make(map[string]bool)
+	for _, ent := range current {
+		for _, member := range entryMembersStatus(ent) {
+			key := MemberStatusKey(ent.GetFqdn(), member.GetIp())
+			seen[key] = true
+			from := ""
+			if prevMember, ok := previousMembers[key]; ok {
+				from = MemberStatusLabel(prevMember)
+			}
+			to := MemberStatusLabel(member)
+			if from == to {
+				continue
+			}
+			events = append(events, &StatusEvent{
+				Time:   now,
+				Fqdn:   ent.GetFqdn(),
+				Ip:     member.GetIp(),
+				Dc:     member.GetDc(),
+				From:   from,
+				To:     to,
+				Reason: member.GetFailureReason(),
+			})
+		}
+	}
+	for _, ent := range previous {
+		for _, member := range entryMembersStatus(ent) {
+			if seen[MemberStatusKey(ent.GetFqdn(), member.GetIp())] {
+				continue
+			}
+			events = append(events, &StatusEvent{
+				Time: now,
+				Fqdn: ent.GetFqdn(),
+				Ip:   member.GetIp(),
+				Dc:   member.GetDc(),
+				From: MemberStatusLabel(member),
+			})
+		}
+	}
+	return events
+}
+
+// UntilHolds tells if watch condition holds for every member, members disabled by user are ignored.
+// Condition does not hold when there is no member to evaluate.
+func UntilHolds(until string, ents []*gslbsvc.GetEntryStatusResponse) bool {
+	evaluated := 0
+	for _, ent := range ents {
+		for _, member := range entryMembersStatus(ent) {
+			if IsDisabledByUser(member) {
+				continue
+			}
+			evaluated++
+			online := member.GetStatus() == gslbsvc.MemberStatus_ONLINE
+			if until == UntilAllOnline && !online {
+				return false
+			}
+			if until == UntilAllOffline && online {
+				return false
+			}
+		}
+	}
+	return evaluated > 0
+}
+
+// Run poll entries status until interrupted or until condition holds, in a terminal render is called
+// on each poll with previous status of members which changed since last poll, otherwise changes are printed as events.
+func (o WatchOptions) Run(
+	output OutputFormat,
+	fetch func(ctx context.Context) ([]*gslbsvc.GetEntryStatusResponse, error),
+	render func(ents []*gslbsvc.GetEntryStatusResponse, transitions map[string]string) error,
+) error {
+	if !output.IsHuman() && output.Format() != OutputJson {
+		return fmt.Errorf("watch mode only supports table, wide and json outputs")
+	}
+	if o.Interval <= 0 {
+		return fmt.Errorf("interval must be positive")
+	}
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer cancel()
+
+	redraw := output.IsHuman() && IsTerminal(os.Stdout)
+	var previous []*gslbsvc.GetEntryStatusResponse
+	for {
+		ents, err := fetch(ctx)
+		if ctx.Err() != nil {
+			return nil
+		}
+		if err != nil {
+			msg.UseStderr()
+			msg.Error(fmt.Sprintf("Failed to get status: %s", err.Error()))
+			msg.UseStdout()
+		} else {
+			events := StatusEvents(previous, ents, time.Now())
+			if redraw {
+				err = o.redraw(ents, events, previous == nil, render)
+			} else {
+				err = writeStatusEvents(msg.Output(), events, output.Format() == OutputJson)
+			}
+			if err != nil {
+				return err
+			}
+			previous = ents
+			if o.Until != "" && UntilHolds(o.Until, ents) {
+				if output.IsHuman() {
+					msg.Successf("Condition %s holds.", o.Until)
+				}
+				return nil
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(o.Interval):
+		}
+	}
+}
+
+func (o WatchOptions) redraw(
+	ents []*gslbsvc.GetEntryStatusResponse,
+	events []*StatusEvent,
+	firstPoll bool,
+	render func(ents []*gslbsvc.GetEntryStatusResponse, transitions map[string]string) error,
+) error {
+	transitions := make(map[string]string)
+	if !firstPoll {
+		for _, event := range events {
+			transitions[MemberStatusKey(event.Fqdn, event.Ip)] = event.From
+		}
+	}
+	fmt.Fprint(msg.Output(), clearScreen)
+	msg.Infof("Every %s, last update at %s (ctrl+c to quit)", o.Interval, time.Now().Format(time.TimeOnly))
+	err := render(ents, transitions)
+	if err != nil {
+		return err
+	}
+	for _, event := range events {
+		if !firstPoll && event.To == "" {
+			msg.Warning(fmt.Sprintf("Member %s of %s has been removed.", event.Ip, event.Fqdn))
+		}
+	}
+	return nil
+}
+
+func writeStatusEvents(w io.Writer, events []*StatusEvent, asJson bool) error {
+	for _, event := range events {
+		line := event.String()
+		if asJson {
+			b, err := json.Marshal(event)
+			if err != nil {
+				return err
+			}
+			line = string(b)
+		}
+		_, err := fmt.Fprintln(w, line)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// TransitionText gives text to highlight a member which changed since previous poll, empty if not changed.
+func TransitionText(transitions map[string]string, fqdn, ip string) string {
+	from, ok := transitions[MemberStatusKey(fqdn, ip)]
+	if !ok {
+		return ""
+	}
+	if from == "" {
+		return msg.Magenta(" [new]").String()
+	}
+	return msg.Magenta(fmt.Sprintf(" [was %s]", from)).String()
+}
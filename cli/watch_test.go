@@ -0,0 +1,61 @@
+package cli
+
+import (
+	"testing"
+	"time"
+
+	gslbsvc "github.com/orange-cloudfoundry/gsloc-go-sdk/gsloc/services/gslb/v1"
+)
+
+func TestStatusEvents(t *testing.T) {
+	now := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	previous := []*gslbsvc.GetEntryStatusResponse{{
+		Fqdn: "a.example.com.",
+		MembersIpv4: []*gslbsvc.MemberStatus{
+			{Ip: "10.0.0.1", Dc: "dc1", Status: gslbsvc.MemberStatus_ONLINE},
+			{Ip: "10.0.0.2", Dc: "dc2", Status: gslbsvc.MemberStatus_ONLINE},
+			{Ip: "10.0.0.3", Dc: "dc2", Status: gslbsvc.MemberStatus_ONLINE},
+		},
+	}}
+	current := []*gslbsvc.GetEntryStatusResponse{{
+		Fqdn: "a.example.com.",
+		MembersIpv4: []*gslbsvc.MemberStatus{
+			{Ip: "10.0.0.1", Dc: "dc1", Status: gslbsvc.MemberStatus_ONLINE},
+			{Ip: "10.0.0.2", Dc: "dc2", Status: gslbsvc.MemberStatus_CHECK_FAILED, FailureReason: "timeout"},
+			{Ip: "10.0.0.4", Dc: "dc2", Status: gslbsvc.MemberStatus_CHECK_FAILED, FailureReason: "disabled entry"},
+		},
+	}}
+
+	events := StatusEvents(previous, current, now)
+	expected := []string{
+		"2023-01-01T00:00:00Z a.example.com. 10.0.0.2 (dc2) ONLINE -> CHECK_FAILED: timeout",
+		"2023-01-01T00:00:00Z a.example.com. 10.0.0.4 (dc2) DISABLED: disabled entry",
+		"2023-01-01T00:00:00Z a.example.com. 10.0.0.3 (dc2) ONLINE -> REMOVED",
+	}
+	if len(events) != len(expected) {
+		t.Fatalf("Expected %d events but got %d: %v", len(expected), len(events), events)
+	}
+	for i, event := range events {
+		if event.String() != expected[i] {
+			t.Errorf("Expected event %q but got %q", expected[i], event.String())
+		}
+	}
+
+	if UntilHolds(UntilAllOnline, current) {
+		t.Errorf("Expected all-online to not hold with a failed member")
+	}
+	if !UntilHolds(UntilAllOnline, previous) {
+		t.Errorf("Expected all-online to hold")
+	}
+	current[0].MembersIpv4 = current[0].MembersIpv4[1:]
+	if !UntilHolds(UntilAllOffline, current) {
+		t.Errorf("Expected all-offline to hold when only failed and disabled members are left")
+	}
+	current[0].MembersIpv4 = current[0].MembersIpv4[1:]
+	if UntilHolds(UntilAllOnline, current) || UntilHolds(UntilAllOffline, current) {
+		t.Errorf("Expected conditions to not hold when only disabled members are left")
+	}
+	if UntilHolds(UntilAllOnline, []*gslbsvc.GetEntryStatusResponse{{Fqdn: "a.example.com."}}) {
+		t.Errorf("Expected all-online to not hold on an entry without members")
+	}
+}
@@ -0,0 +1,420 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/AlecAivazis/survey/v2"
+	"github.com/ArthurHlt/go-flags"
+	"github.com/orange-cloudfoundry/gsloc-go-sdk/gsloc/api/config/entries/v1"
+	gslbsvc "github.com/orange-cloudfoundry/gsloc-go-sdk/gsloc/services/gslb/v1"
+	gsloctype "github.com/orange-cloudfoundry/gsloc-go-sdk/gsloc/type/v1"
+	"net"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	lbAlgoChoices      = []string{"ROUND_ROBIN", "TOPOLOGY", "RATIO", "RANDOM"}
+	hcTypeChoices      = []string{"HTTP", "TCP", "GRPC", "ICMP", "UDP", "PLUGIN", "NO_HEALTHCHECK"}
+	httpMethodChoices  = []string{"GET", "HEAD", "POST", "PUT", "DELETE", "OPTIONS", "TRACE", "PATCH"}
+	codecClientChoices = enumNames(gsloctype.CodecClientType(0).Descriptor(), nil)
+)
+
+const payloadHelp = "Start with @ to read payload from a file, leave empty for none."
+
+// askOne write prompt on stderr to not mix it with results on stdout.
+func askOne(prompt survey.Prompt, response any, opts ...survey.AskOpt) error {
+	return survey.AskOne(prompt, response, append(opts, survey.WithStdio(os.Stdin, os.Stderr, os.Stderr))...)
+}
+
+func askString(message, help string, value *string, opts ...survey.AskOpt) error {
+	return askOne(&survey.Input{
+		Message: message,
+		Help:    help,
+		Default: *value,
+	}, value, opts...)
+}
+
+func askChoice(message string, choices []string, value *string) error {
+	prompt := &survey.Select{
+		Message: message,
+		Options: choices,
+	}
+	for _, choice := range choices {
+		if choice == *value {
+			prompt.Default = choice
+		}
+	}
+	return askOne(prompt, value)
+}
+
+func askUint32(message string, value *uint32) error {
+	answer := strconv.FormatUint(uint64(*value), 10)
+	err := askString(message, "", &answer, survey.WithValidator(func(ans any) error {
+		_, err := strconv.ParseUint(ans.(string), 10, 32)
+		return err
+	}))
+	if err != nil {
+		return err
+	}
+	parsed, _ := strconv.ParseUint(answer, 10, 32)
+	*value = uint32(parsed)
+	return nil
+}
+
+func askDuration(message string, value *string) error {
+	return askString(message, "Duration, e.g. 500ms, 10s or 1m.", value, survey.WithValidator(func(ans any) error {
+		_, err := time.ParseDuration(ans.(string))
+		return err
+	}))
+}
+
+func askList(message, help string, values *[]string, opts ...survey.AskOpt) error {
+	answer := strings.Join(*values, ",")
+	err := askString(message, help, &answer, opts...)
+	if err != nil {
+		return err
+	}
+	*values = splitList(answer)
+	return nil
+}
+
+func splitList(s string) []string {
+	values := make([]string, 0)
+	for _, value := range strings.Split(s, ",") {
+		value = strings.TrimSpace(value)
+		if value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+// Ask fill entry flags with prompts, current values are proposed as defaults.
+func (c *EntryFlags) Ask() error {
+	err := askChoice("LB algo preferred:", lbAlgoChoices, &c.LBAlgoPreferred)
+	if err != nil {
+		return err
+	}
+	err = askChoice("LB algo alternate:", lbAlgoChoices, &c.LBAlgoAlternate)
+	if err != nil {
+		return err
+	}
+	err = askChoice("LB algo fallback:", lbAlgoChoices, &c.LBAlgoFallback)
+	if err != nil {
+		return err
+	}
+	err = askUint32("TTL in seconds:", &c.TTL)
+	if err != nil {
+		return err
+	}
+	err = askUint32("Max answer returned (0 for all):", &c.MaxAnswerReturned)
+	if err != nil {
+		return err
+	}
+	return askList("Tags (comma separated):", "", &c.Tags)
+}
+
+// Ask fill healthcheck flags with prompts, questions depend on healthcheck type.
+func (c *HealthcheckFlags) Ask(plugins []*gslbsvc.PluginHealthCheckInfo) error {
+	err := askChoice("Healthcheck type:", hcTypeChoices, &c.HcType)
+	if err != nil {
+		return err
+	}
+	if c.HcType == "NO_HEALTHCHECK" {
+		return nil
+	}
+	if c.HcType == "PLUGIN" && len(plugins) == 0 {
+		return fmt.Errorf("no healthcheck plugin available on target")
+	}
+	err = askUint32("Healthcheck port:", &c.HcPort)
+	if err != nil {
+		return err
+	}
+	err = askDuration("Healthcheck timeout:", &c.HcTimeout)
+	if err != nil {
+		return err
+	}
+	err = askDuration("Healthcheck interval:", &c.HcInterval)
+	if err != nil {
+		return err
+	}
+	err = askOne(&survey.Confirm{Message: "Enable TLS?", Default: c.HcEnableTls}, &c.HcEnableTls)
+	if err != nil {
+		return err
+	}
+	if c.HcEnableTls {
+		err = askString("TLS server name:", "Leave empty to use fqdn without trailing dot.", &c.HcTlsServerName)
+		if err != nil {
+			return err
+		}
+		ca := string(c.HcTlsCa)
+		err = askString("TLS CA file path:", "Leave empty to use system CAs.", &ca)
+		if err != nil {
+			return err
+		}
+		c.HcTlsCa = flags.Filename(ca)
+	}
+
+	switch c.HcType {
+	case "HTTP":
+		return c.askHttp()
+	case "TCP":
+		err = askString("TCP send payload:", payloadHelp, &c.TcpSendPayload)
+		if err != nil {
+			return err
+		}
+		return askList("TCP receive payloads (comma separated):", payloadHelp, &c.TcpReceivePayloads)
+	case "GRPC":
+		err = askString("gRPC service name:", "", &c.GRPCServiceName)
+		if err != nil {
+			return err
+		}
+		return askString("gRPC authority:", "Leave empty to use fqdn.", &c.GRPCAuthority)
+	case "ICMP":
+		return askDuration("ICMP delay:", &c.IcmpDelay)
+	case "UDP":
+		err = askString("UDP send payload:", payloadHelp, &c.UdpSendPayload)
+		if err != nil {
+			return err
+		}
+		err = askList("UDP receive payloads (comma separated):", payloadHelp, &c.UdpReceivePayloads)
+		if err != nil {
+			return err
+		}
+		err = askDuration("UDP delay:", &c.UdpDelay)
+		if err != nil {
+			return err
+		}
+		return askDuration("UDP ping timeout:", &c.UdpPingTimeout)
+	case "PLUGIN":
+		return c.askPlugin(plugins)
+	}
+	return nil
+}
+
+func (c *HealthcheckFlags) askHttp() error {
+	if c.HttpPath == "" {
+		c.HttpPath = "/"
+	}
+	err := askString("HTTP path:", "", &c.HttpPath, survey.WithValidator(survey.Required))
+	if err != nil {
+		return err
+	}
+	err = askString("HTTP host:", "Leave empty to use fqdn.", &c.HttpHost)
+	if err != nil {
+		return err
+	}
+	err = askChoice("HTTP method:", httpMethodChoices, &c.HttpMethod)
+	if err != nil {
+		return err
+	}
+	err = askChoice("HTTP codec client type:", codecClientChoices, &c.HttpCodecClientType)
+	if err != nil {
+		return err
+	}
+	if c.HttpCodeRange == "" {
+		code := c.HttpCode
+		if code == 0 {
+			code = 200
+		}
+		c.HttpCodeRange = fmt.Sprintf("%d-%d", code, code)
+	}
+	err = askString("HTTP expected status codes range:", "e.g. 200-299", &c.HttpCodeRange)
+	if err != nil {
+		return err
+	}
+	c.HttpCode = 0
+	err = askString("HTTP send payload:", payloadHelp, &c.HttpSendPayload)
+	if err != nil {
+		return err
+	}
+	return askString("HTTP receive payload:", payloadHelp, &c.HttpReceivePayload)
+}
+
+func (c *HealthcheckFlags) askPlugin(plugins []*gslbsvc.PluginHealthCheckInfo) error {
+	names := make([]string, len(plugins))
+	for i, plugin := range plugins {
+		names[i] = plugin.GetName()
+	}
+	prompt := &survey.Select{
+		Message: "Plugin:",
+		Options: names,
+		Description: func(value string, index int) string {
+			return plugins[index].GetDescription()
+		},
+	}
+	for _, name := range names {
+		if name == c.PluginName {
+			prompt.Default = name
+		}
+	}
+	err := askOne(prompt, &c.PluginName)
+	if err != nil {
+		return err
+	}
+	return c.askPluginOptions()
+}
+
+// askPluginOptions ask value of each current option and let add new ones, values are json or plain strings.
+func (c *HealthcheckFlags) askPluginOptions() error {
+	options := make(map[string]any)
+	if c.PluginJsonOpts != "" {
+		b, err := os.ReadFile(string(c.PluginJsonOpts))
+		if err != nil {
+			return fmt.Errorf("failed to read plugin options file: %s", err)
+		}
+		err = json.Unmarshal(b, &options)
+		if err != nil {
+			return fmt.Errorf("failed to unmarshal plugin options file: %s", err)
+		}
+	}
+	keys := make([]string, 0, len(options))
+	for key := range options {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		value, err := askOptionValue(fmt.Sprintf("Plugin option %s:", key), options[key])
+		if err != nil {
+			return err
+		}
+		options[key] = value
+	}
+	for {
+		add := false
+		err := askOne(&survey.Confirm{Message: "Add a plugin option?"}, &add)
+		if err != nil {
+			return err
+		}
+		if !add {
+			break
+		}
+		key := ""
+		err = askString("Plugin option name:", "", &key, survey.WithValidator(survey.Required))
+		if err != nil {
+			return err
+		}
+		options[key], err = askOptionValue(fmt.Sprintf("Plugin option %s:", key), options[key])
+		if err != nil {
+			return err
+		}
+	}
+	if len(options) == 0 {
+		c.PluginJsonOpts = ""
+		return nil
+	}
+	b, err := json.Marshal(options)
+	if err != nil {
+		return err
+	}
+	path, err := c.TempFile("plugin-opts-*.json", b)
+	if err != nil {
+		return err
+	}
+	c.PluginJsonOpts = flags.Filename(path)
+	return nil
+}
+
+func askOptionValue(message string, current any) (any, error) {
+	answer := ""
+	if current != nil {
+		b, err := json.Marshal(current)
+		if err != nil {
+			return nil, err
+		}
+		answer = string(b)
+	}
+	err := askString(message, "Json value (e.g. 10, true, [\"a\"]) or plain string.", &answer)
+	if err != nil {
+		return nil, err
+	}
+	var value any
+	if json.Unmarshal([]byte(answer), &value) != nil {
+		return answer, nil
+	}
+	return value, nil
+}
+
+// AskMembers ask datacenters and ips of members, ratio and state of current members are kept.
+func AskMembers(dcs []string, current []*entries.Member) ([]*entries.Member, error) {
+	currentByIp := make(map[string]*entries.Member)
+	ipsByDc := make(map[string][]string)
+	selectedDcs := make([]string, 0)
+	for _, member := range current {
+		currentByIp[member.GetIp()] = member
+		if _, ok := ipsByDc[member.GetDc()]; !ok {
+			selectedDcs = append(selectedDcs, member.GetDc())
+		}
+		ipsByDc[member.GetDc()] = append(ipsByDc[member.GetDc()], member.GetIp())
+	}
+	if len(dcs) == 0 {
+		return nil, fmt.Errorf("no datacenter found on target")
+	}
+	err := askOne(&survey.MultiSelect{
+		Message: "Datacenters of members:",
+		Options: dcs,
+		Default: selectedDcs,
+	}, &selectedDcs)
+	if err != nil {
+		return nil, err
+	}
+
+	members := make([]*entries.Member, 0)
+	for _, dc := range selectedDcs {
+		ips := ipsByDc[dc]
+		err = askList(fmt.Sprintf("IPs of members in %s (comma separated):", dc), "", &ips, survey.WithValidator(validateIps))
+		if err != nil {
+			return nil, err
+		}
+		for _, ip := range ips {
+			member := &entries.Member{Ip: ip, Dc: dc}
+			if prev, ok := currentByIp[ip]; ok {
+				member.Ratio = prev.GetRatio()
+				member.Disabled = prev.GetDisabled()
+			}
+			members = append(members, member)
+		}
+	}
+	return members, nil
+}
+
+// setMembers put members in the list of their ip family.
+func setMembers(ent *entries.Entry, members []*entries.Member) {
+	ent.MembersIpv4 = make([]*entries.Member, 0)
+	ent.MembersIpv6 = make([]*entries.Member, 0)
+	for _, member := range members {
+		if isIpv4(member.GetIp()) {
+			ent.MembersIpv4 = append(ent.MembersIpv4, member)
+		} else {
+			ent.MembersIpv6 = append(ent.MembersIpv6, member)
+		}
+	}
+}
+
+func validateIps(ans any) error {
+	for _, ip := range splitList(ans.(string)) {
+		if net.ParseIP(ip) == nil {
+			return fmt.Errorf("invalid ip %s", ip)
+		}
+	}
+	return nil
+}
+
+func isIpv4(ip string) bool {
+	parsed := net.ParseIP(ip)
+	return parsed != nil && parsed.To4() != nil
+}
+
+// FromEntry set flags from an entry, used to propose current values.
+func (c *EntryFlags) FromEntry(ent *entries.Entry) {
+	c.LBAlgoPreferred = ent.GetLbAlgoPreferred().String()
+	c.LBAlgoAlternate = ent.GetLbAlgoAlternate().String()
+	c.LBAlgoFallback = ent.GetLbAlgoFallback().String()
+	c.MaxAnswerReturned = ent.GetMaxAnswerReturned()
+	c.TTL = ent.GetTtl()
+	c.Tags = ent.GetTags()
+}
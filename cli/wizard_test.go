@@ -0,0 +1,19 @@
+package cli
+
+import (
+	"testing"
+
+	"github.com/orange-cloudfoundry/gsloc-go-sdk/gsloc/api/config/entries/v1"
+)
+
+func TestSetMembers(t *testing.T) {
+	ent := &entries.Entry{}
+	setMembers(ent, []*entries.Member{
+		{Ip: "10.0.0.1", Dc: "dc1"},
+		{Ip: "2001:db8::1", Dc: "dc1"},
+		{Ip: "10.0.0.2", Dc: "dc2"},
+	})
+	if len(ent.GetMembersIpv4()) != 2 || len(ent.GetMembersIpv6()) != 1 {
+		t.Errorf("Unexpected members split %v %v", ent.GetMembersIpv4(), ent.GetMembersIpv6())
+	}
+}
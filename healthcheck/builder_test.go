@@ -0,0 +1,166 @@
+package healthcheck
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ArthurHlt/go-flags"
+	"github.com/orange-cloudfoundry/gsloc-go-sdk/gsloc/api/config/core/v1"
+	hcconf "github.com/orange-cloudfoundry/gsloc-go-sdk/gsloc/api/config/healthchecks/v1"
+	gsloctype "github.com/orange-cloudfoundry/gsloc-go-sdk/gsloc/type/v1"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+func textPayload(txt string) *hcconf.HealthCheckPayload {
+	return &hcconf.HealthCheckPayload{Payload: &hcconf.HealthCheckPayload_Text{Text: txt}}
+}
+
+func TestBuild(t *testing.T) {
+	dir := t.TempDir()
+	binaryFile := filepath.Join(dir, "payload.bin")
+	optsFile := filepath.Join(dir, "opts.json")
+	caFile := filepath.Join(dir, "ca.pem")
+	for path, content := range map[string]string{binaryFile: "\x00\x01", optsFile: `{"retries": 2, "mode": "strict"}`, caFile: "my-ca"} {
+		if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	options, err := structpb.NewStruct(map[string]any{"retries": 2.0, "mode": "strict"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		name     string
+		builder  Builder
+		expected *hcconf.HealthCheck
+	}{
+		{
+			name: "HTTP with code",
+			builder: Builder{
+				HcType:              "HTTP",
+				HttpHost:            "a.example.com",
+				HttpPath:            "/health",
+				HttpCode:            204,
+				HttpHeaders:         map[string]string{"X-Check": "1"},
+				HttpMethod:          "HEAD",
+				HttpCodecClientType: "HTTP2",
+				HttpSendPayload:     "ping",
+				HttpReceivePayload:  "@" + binaryFile,
+			},
+			expected: &hcconf.HealthCheck{HealthChecker: &hcconf.HealthCheck_HttpHealthCheck{HttpHealthCheck: &hcconf.HttpHealthCheck{
+				Host:                "a.example.com",
+				Path:                "/health",
+				Send:                textPayload("ping"),
+				Receive:             &hcconf.HealthCheckPayload{Payload: &hcconf.HealthCheckPayload_Binary{Binary: []byte{0, 1}}},
+				RequestHeadersToAdd: []*core.HeaderValueOption{{Header: &core.HeaderValue{Key: "X-Check", Value: "1"}}},
+				ExpectedStatuses:    &gsloctype.Int64Range{Start: 204, End: 204},
+				CodecClientType:     gsloctype.CodecClientType_HTTP2,
+				Method:              hcconf.RequestMethod_HEAD,
+			}}},
+		},
+		{
+			name:    "HTTP with range",
+			builder: Builder{HcType: "HTTP", HttpPath: "/", HttpCodeRange: "200-299", HttpMethod: "GET"},
+			expected: &hcconf.HealthCheck{HealthChecker: &hcconf.HealthCheck_HttpHealthCheck{HttpHealthCheck: &hcconf.HttpHealthCheck{
+				Path:                "/",
+				RequestHeadersToAdd: []*core.HeaderValueOption{},
+				ExpectedStatuses:    &gsloctype.Int64Range{Start: 200, End: 299},
+				Method:              hcconf.RequestMethod_GET,
+			}}},
+		},
+		{
+			name:    "HTTP default status",
+			builder: Builder{HcType: "HTTP", HttpPath: "/"},
+			expected: &hcconf.HealthCheck{HealthChecker: &hcconf.HealthCheck_HttpHealthCheck{HttpHealthCheck: &hcconf.HttpHealthCheck{
+				Path:                "/",
+				RequestHeadersToAdd: []*core.HeaderValueOption{},
+				ExpectedStatuses:    &gsloctype.Int64Range{Start: 200, End: 200},
+			}}},
+		},
+		{
+			name:    "TCP",
+			builder: Builder{HcType: "TCP", TcpSendPayload: "ping", TcpReceivePayloads: []string{"pong", "PONG"}},
+			expected: &hcconf.HealthCheck{HealthChecker: &hcconf.HealthCheck_TcpHealthCheck{TcpHealthCheck: &hcconf.TcpHealthCheck{
+				Send:    textPayload("ping"),
+				Receive: []*hcconf.HealthCheckPayload{textPayload("pong"), textPayload("PONG")},
+			}}},
+		},
+		{
+			name:    "GRPC",
+			builder: Builder{HcType: "GRPC", GRPCServiceName: "svc", GRPCAuthority: "a.example.com"},
+			expected: &hcconf.HealthCheck{HealthChecker: &hcconf.HealthCheck_GrpcHealthCheck{GrpcHealthCheck: &hcconf.GrpcHealthCheck{
+				ServiceName: "svc",
+				Authority:   "a.example.com",
+			}}},
+		},
+		{
+			name:    "ICMP",
+			builder: Builder{HcType: "ICMP", IcmpDelay: "0s"},
+			expected: &hcconf.HealthCheck{HealthChecker: &hcconf.HealthCheck_IcmpHealthCheck{IcmpHealthCheck: &hcconf.IcmpHealthCheck{
+				Delay: durationpb.New(time.Second),
+			}}},
+		},
+		{
+			name:    "UDP",
+			builder: Builder{HcType: "UDP", UdpSendPayload: "ping", UdpReceivePayloads: []string{"pong"}, UdpDelay: "2s", UdpPingTimeout: "0s"},
+			expected: &hcconf.HealthCheck{HealthChecker: &hcconf.HealthCheck_UdpHealthCheck{UdpHealthCheck: &hcconf.UdpHealthCheck{
+				Send:        textPayload("ping"),
+				Receive:     []*hcconf.HealthCheckPayload{textPayload("pong")},
+				PingTimeout: durationpb.New(5 * time.Second),
+				Delay:       durationpb.New(2 * time.Second),
+			}}},
+		},
+		{
+			name:    "PLUGIN",
+			builder: Builder{HcType: "PLUGIN", PluginName: "my-plugin", PluginJsonOpts: flags.Filename(optsFile)},
+			expected: &hcconf.HealthCheck{HealthChecker: &hcconf.HealthCheck_PluginHealthCheck{PluginHealthCheck: &hcconf.PluginHealthCheck{
+				Name:    "my-plugin",
+				Options: options,
+			}}},
+		},
+		{
+			name:     "NO_HEALTHCHECK",
+			builder:  Builder{HcType: "NO_HEALTHCHECK"},
+			expected: &hcconf.HealthCheck{HealthChecker: &hcconf.HealthCheck_NoHealthCheck{NoHealthCheck: &hcconf.NoHealthCheck{}}},
+		},
+	}
+	for _, tc := range cases {
+		tc.builder.HcTimeout = "5s"
+		tc.builder.HcInterval = "30s"
+		tc.builder.HcPort = 8080
+		tc.builder.HcEnableTls = true
+		tc.builder.HcTlsCa = flags.Filename(caFile)
+		hc, err := tc.builder.Build()
+		if err != nil {
+			t.Errorf("%s: unexpected error %s", tc.name, err)
+			continue
+		}
+		expected := tc.expected
+		expected.Timeout = durationpb.New(5 * time.Second)
+		expected.Interval = durationpb.New(30 * time.Second)
+		expected.Port = 8080
+		expected.TlsConfig = &hcconf.TlsConfig{Enable: true, Ca: "my-ca"}
+		if !proto.Equal(hc, expected) {
+			t.Errorf("%s: expected\n%v\ngot\n%v", tc.name, expected, hc)
+		}
+	}
+}
+
+func TestBuildErrors(t *testing.T) {
+	cases := map[string]Builder{
+		"invalid timeout":    {HcTimeout: "10", HcInterval: "30s", HcType: "TCP"},
+		"invalid code range": {HcTimeout: "10s", HcInterval: "30s", HcType: "HTTP", HttpCodeRange: "200"},
+		"missing plugin":     {HcTimeout: "10s", HcInterval: "30s", HcType: "PLUGIN"},
+		"missing payload":    {HcTimeout: "10s", HcInterval: "30s", HcType: "TCP", TcpSendPayload: "@/does/not/exist"},
+	}
+	for name, builder := range cases {
+		if _, err := builder.Build(); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
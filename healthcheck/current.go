@@ -0,0 +1,157 @@
+package healthcheck
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/ArthurHlt/go-flags"
+	hcconf "github.com/orange-cloudfoundry/gsloc-go-sdk/gsloc/api/config/healthchecks/v1"
+	"os"
+	"strings"
+)
+
+// FromHealthcheck set flags from a healthcheck, used to propose current values.
+// Values which flags take as files (ca, binary payloads and plugin options) are written in temporary files,
+// Cleanup must be called to remove them.
+func (c *Builder) FromHealthcheck(hc *hcconf.HealthCheck) error {
+	var err error
+	c.HcTimeout = hc.GetTimeout().AsDuration().String()
+	c.HcInterval = hc.GetInterval().AsDuration().String()
+	c.HcPort = hc.GetPort()
+	c.HcEnableTls = hc.GetTlsConfig().GetEnable()
+	c.HcTlsServerName = hc.GetTlsConfig().GetServerName()
+	c.HcTlsCa = ""
+	if hc.GetTlsConfig().GetCa() != "" {
+		path, err := c.TempFile("ca-*.pem", []byte(hc.GetTlsConfig().GetCa()))
+		if err != nil {
+			return err
+		}
+		c.HcTlsCa = flags.Filename(path)
+	}
+
+	switch checker := hc.GetHealthChecker().(type) {
+	case *hcconf.HealthCheck_HttpHealthCheck:
+		httpHc := checker.HttpHealthCheck
+		c.HcType = "HTTP"
+		c.HttpHost = httpHc.GetHost()
+		c.HttpPath = httpHc.GetPath()
+		c.HttpMethod = httpHc.GetMethod().String()
+		c.HttpCodecClientType = httpHc.GetCodecClientType().String()
+		c.HttpCode = 0
+		c.HttpCodeRange = ""
+		if httpHc.GetExpectedStatuses() != nil {
+			c.HttpCodeRange = fmt.Sprintf("%d-%d", httpHc.GetExpectedStatuses().GetStart(), httpHc.GetExpectedStatuses().GetEnd())
+		}
+		c.HttpHeaders = make(map[string]string)
+		for _, header := range httpHc.GetRequestHeadersToAdd() {
+			c.HttpHeaders[header.GetHeader().GetKey()] = header.GetHeader().GetValue()
+		}
+		c.HttpSendPayload, err = c.payloadFlag(httpHc.GetSend())
+		if err != nil {
+			return err
+		}
+		c.HttpReceivePayload, err = c.payloadFlag(httpHc.GetReceive())
+		if err != nil {
+			return err
+		}
+	case *hcconf.HealthCheck_TcpHealthCheck:
+		c.HcType = "TCP"
+		c.TcpSendPayload, err = c.payloadFlag(checker.TcpHealthCheck.GetSend())
+		if err != nil {
+			return err
+		}
+		c.TcpReceivePayloads, err = c.payloadFlags(checker.TcpHealthCheck.GetReceive())
+		if err != nil {
+			return err
+		}
+	case *hcconf.HealthCheck_GrpcHealthCheck:
+		c.HcType = "GRPC"
+		c.GRPCServiceName = checker.GrpcHealthCheck.GetServiceName()
+		c.GRPCAuthority = checker.GrpcHealthCheck.GetAuthority()
+	case *hcconf.HealthCheck_IcmpHealthCheck:
+		c.HcType = "ICMP"
+		c.IcmpDelay = checker.IcmpHealthCheck.GetDelay().AsDuration().String()
+	case *hcconf.HealthCheck_UdpHealthCheck:
+		c.HcType = "UDP"
+		c.UdpDelay = checker.UdpHealthCheck.GetDelay().AsDuration().String()
+		c.UdpPingTimeout = checker.UdpHealthCheck.GetPingTimeout().AsDuration().String()
+		c.UdpSendPayload, err = c.payloadFlag(checker.UdpHealthCheck.GetSend())
+		if err != nil {
+			return err
+		}
+		c.UdpReceivePayloads, err = c.payloadFlags(checker.UdpHealthCheck.GetReceive())
+		if err != nil {
+			return err
+		}
+	case *hcconf.HealthCheck_PluginHealthCheck:
+		c.HcType = "PLUGIN"
+		c.PluginName = checker.PluginHealthCheck.GetName()
+		c.PluginJsonOpts = ""
+		if len(checker.PluginHealthCheck.GetOptions().GetFields()) > 0 {
+			b, err := json.Marshal(checker.PluginHealthCheck.GetOptions().AsMap())
+			if err != nil {
+				return err
+			}
+			path, err := c.TempFile("plugin-opts-*.json", b)
+			if err != nil {
+				return err
+			}
+			c.PluginJsonOpts = flags.Filename(path)
+		}
+	default:
+		c.HcType = "NO_HEALTHCHECK"
+	}
+	return nil
+}
+
+// payloadFlag gives payload as a flag value, binary payloads and texts starting with @ are given as a file.
+func (c *Builder) payloadFlag(payload *hcconf.HealthCheckPayload) (string, error) {
+	if payload == nil {
+		return "", nil
+	}
+	content := []byte(payload.GetText())
+	if binary, ok := payload.GetPayload().(*hcconf.HealthCheckPayload_Binary); ok {
+		content = binary.Binary
+	} else if !strings.HasPrefix(payload.GetText(), "@") {
+		return payload.GetText(), nil
+	}
+	path, err := c.TempFile("payload-*", content)
+	if err != nil {
+		return "", err
+	}
+	return "@" + path, nil
+}
+
+func (c *Builder) payloadFlags(payloads []*hcconf.HealthCheckPayload) ([]string, error) {
+	values := make([]string, 0, len(payloads))
+	for _, payload := range payloads {
+		value, err := c.payloadFlag(payload)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+	}
+	return values, nil
+}
+
+// TempFile write content in a temporary file removed by Cleanup.
+func (c *Builder) TempFile(pattern string, content []byte) (string, error) {
+	f, err := os.CreateTemp("", "gsloc-"+pattern)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close() // nolint:errcheck
+	c.tmpFiles = append(c.tmpFiles, f.Name())
+	_, err = f.Write(content)
+	if err != nil {
+		return "", err
+	}
+	return f.Name(), nil
+}
+
+// Cleanup remove temporary files made by FromHealthcheck and prompts.
+func (c *Builder) Cleanup() {
+	for _, path := range c.tmpFiles {
+		os.Remove(path) // nolint:errcheck
+	}
+	c.tmpFiles = nil
+}
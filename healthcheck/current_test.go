@@ -0,0 +1,75 @@
+package healthcheck
+
+import (
+	"os"
+	"testing"
+
+	hcconf "github.com/orange-cloudfoundry/gsloc-go-sdk/gsloc/api/config/healthchecks/v1"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+func TestFromHealthcheck(t *testing.T) {
+	options, err := structpb.NewStruct(map[string]any{"threshold": 3.0, "mode": "strict"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	base := Builder{
+		HcTimeout:           "5s",
+		HcInterval:          "1m0s",
+		HcPort:              8443,
+		HcEnableTls:         true,
+		HcTlsServerName:     "a.example.com",
+		HttpHost:            "a.example.com",
+		HttpPath:            "/health",
+		HttpCodeRange:       "200-299",
+		HttpHeaders:         map[string]string{"X-Check": "1"},
+		HttpMethod:          "POST",
+		HttpCodecClientType: "HTTP2",
+		HttpSendPayload:     "ping",
+		TcpSendPayload:      "ping",
+		TcpReceivePayloads:  []string{"pong", "PONG"},
+		GRPCServiceName:     "svc",
+		IcmpDelay:           "2s",
+		UdpReceivePayloads:  []string{"pong"},
+		UdpDelay:            "1s",
+		UdpPingTimeout:      "3s",
+		PluginName:          "my-plugin",
+	}
+	for _, hcType := range []string{"HTTP", "TCP", "GRPC", "ICMP", "UDP", "PLUGIN", "NO_HEALTHCHECK"} {
+		builder := base
+		builder.HcType = hcType
+		hc, err := builder.Build()
+		if err != nil {
+			t.Fatal(err)
+		}
+		// values only given by files
+		hc.TlsConfig.Ca = "-----BEGIN CERTIFICATE-----"
+		if plugin := hc.GetPluginHealthCheck(); plugin != nil {
+			plugin.Options = options
+		}
+		if tcp := hc.GetTcpHealthCheck(); tcp != nil {
+			tcp.Send = &hcconf.HealthCheckPayload{Payload: &hcconf.HealthCheckPayload_Binary{Binary: []byte{0, 1, 2}}}
+		}
+
+		current := &Builder{}
+		err = current.FromHealthcheck(hc)
+		if err != nil {
+			t.Fatal(err)
+		}
+		tmpFiles := current.tmpFiles
+		fromFlags, err := current.Build()
+		current.Cleanup()
+		if err != nil {
+			t.Fatalf("Failed to make %s healthcheck from current values: %s", hcType, err)
+		}
+		if !proto.Equal(hc, fromFlags) {
+			t.Errorf("Healthcheck %s made from current values differs:\n%v\n%v", hcType, hc, fromFlags)
+		}
+		for _, path := range tmpFiles {
+			if _, err := os.Stat(path); !os.IsNotExist(err) {
+				t.Errorf("Expected temporary file %s to be removed", path)
+			}
+		}
+	}
+}
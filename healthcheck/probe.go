@@ -0,0 +1,408 @@
+package healthcheck
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	hcconf "github.com/orange-cloudfoundry/gsloc-go-sdk/gsloc/api/config/healthchecks/v1"
+	gsloctype "github.com/orange-cloudfoundry/gsloc-go-sdk/gsloc/type/v1"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/icmp"
+	"golang.org/x/net/ipv4"
+	"golang.org/x/net/ipv6"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	defaultTimeout = 10 * time.Second
+	// defaultUdpSend is the payload sent by gsloc when udp healthcheck has no send payload.
+	defaultUdpSend = "test-gohc"
+	// maxReadSize limits what is read from members to find expected payloads.
+	maxReadSize = 1 << 20
+)
+
+// ErrNotTestable is returned by Probe when healthcheck can only be run by gsloc itself.
+var ErrNotTestable = errors.New("healthcheck can't be run locally")
+
+// Probe runs healthcheck once from local host against a member ip, nil is returned when member is healthy.
+// Fqdn is used as default host, authority and tls server name like gsloc does.
+func Probe(ctx context.Context, hc *hcconf.HealthCheck, fqdn, ip string) error {
+	timeout := hc.GetTimeout().AsDuration()
+	if timeout <= 0 {
+		timeout = defaultTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	host := strings.TrimSuffix(fqdn, ".")
+	addr := net.JoinHostPort(ip, strconv.Itoa(int(hc.GetPort())))
+	tlsConf, err := makeTlsConfig(hc.GetTlsConfig(), host)
+	if err != nil {
+		return err
+	}
+
+	switch checker := hc.GetHealthChecker().(type) {
+	case *hcconf.HealthCheck_HttpHealthCheck:
+		return probeHttp(ctx, checker.HttpHealthCheck, tlsConf, host, addr)
+	case *hcconf.HealthCheck_TcpHealthCheck:
+		return probeTcp(ctx, checker.TcpHealthCheck, tlsConf, addr)
+	case *hcconf.HealthCheck_UdpHealthCheck:
+		return probeUdp(ctx, checker.UdpHealthCheck, addr)
+	case *hcconf.HealthCheck_GrpcHealthCheck:
+		return probeGrpc(ctx, checker.GrpcHealthCheck, tlsConf, host, addr)
+	case *hcconf.HealthCheck_IcmpHealthCheck:
+		return probeIcmp(ctx, ip)
+	case *hcconf.HealthCheck_PluginHealthCheck:
+		return fmt.Errorf("%w: plugin %s is only available on gsloc", ErrNotTestable, checker.PluginHealthCheck.GetName())
+	default:
+		return fmt.Errorf("%w: no healthcheck is set, members are always healthy", ErrNotTestable)
+	}
+}
+
+// makeTlsConfig gives nil when tls is not enabled.
+func makeTlsConfig(conf *hcconf.TlsConfig, host string) (*tls.Config, error) {
+	if !conf.GetEnable() {
+		return nil, nil
+	}
+	tlsConf := &tls.Config{
+		ServerName: conf.GetServerName(),
+	}
+	if tlsConf.ServerName == "" {
+		tlsConf.ServerName = host
+	}
+	if conf.GetCa() != "" {
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM([]byte(conf.GetCa())) {
+			return nil, fmt.Errorf("invalid tls ca: no pem certificate found")
+		}
+		tlsConf.RootCAs = pool
+	}
+	return tlsConf, nil
+}
+
+func probeHttp(ctx context.Context, hc *hcconf.HttpHealthCheck, tlsConf *tls.Config, host, addr string) error {
+	scheme := "http"
+	if tlsConf != nil {
+		scheme = "https"
+	}
+	method := http.MethodGet
+	if hc.GetMethod() != hcconf.RequestMethod_METHOD_UNSPECIFIED {
+		method = hc.GetMethod().String()
+	}
+	var body io.Reader
+	if hc.GetSend() != nil {
+		body = bytes.NewReader(PayloadBytes(hc.GetSend()))
+	}
+	req, err := http.NewRequestWithContext(ctx, method, scheme+"://"+addr+hc.GetPath(), body)
+	if err != nil {
+		return err
+	}
+	req.Host = host
+	if hc.GetHost() != "" {
+		req.Host = hc.GetHost()
+	}
+	for _, header := range hc.GetRequestHeadersToAdd() {
+		if header.GetAppend() {
+			req.Header.Add(header.GetHeader().GetKey(), header.GetHeader().GetValue())
+			continue
+		}
+		req.Header.Set(header.GetHeader().GetKey(), header.GetHeader().GetValue())
+	}
+
+	transport, err := makeHttpTransport(hc.GetCodecClientType(), tlsConf)
+	if err != nil {
+		return err
+	}
+	resp, err := (&http.Client{
+		Transport: transport,
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}).Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close() // nolint:errcheck
+
+	if !StatusExpected(hc.GetExpectedStatuses(), resp.StatusCode) {
+		return fmt.Errorf("unexpected status code %d, expected %s", resp.StatusCode, rangeText(hc.GetExpectedStatuses()))
+	}
+	if hc.GetReceive() == nil {
+		return nil
+	}
+	content, err := io.ReadAll(io.LimitReader(resp.Body, maxReadSize))
+	if err != nil {
+		return fmt.Errorf("failed to read body: %s", err)
+	}
+	if !bytes.Contains(content, PayloadBytes(hc.GetReceive())) {
+		return fmt.Errorf("body does not contain %s", payloadText(hc.GetReceive()))
+	}
+	return nil
+}
+
+func makeHttpTransport(codec gsloctype.CodecClientType, tlsConf *tls.Config) (http.RoundTripper, error) {
+	switch codec {
+	case gsloctype.CodecClientType_HTTP2:
+		transport := &http2.Transport{
+			TLSClientConfig: tlsConf,
+		}
+		if tlsConf == nil {
+			// prior knowledge http2 without tls (h2c)
+			transport.AllowHTTP = true
+			transport.DialTLSContext = func(ctx context.Context, network, addr string, _ *tls.Config) (net.Conn, error) {
+				return (&net.Dialer{}).DialContext(ctx, network, addr)
+			}
+		}
+		return transport, nil
+	case gsloctype.CodecClientType_HTTP3:
+		return nil, fmt.Errorf("%w: codec client type HTTP3 is not supported locally", ErrNotTestable)
+	default:
+		return &http.Transport{
+			TLSClientConfig: tlsConf,
+			// an empty map disable http2 upgrade
+			TLSNextProto:      make(map[string]func(string, *tls.Conn) http.RoundTripper),
+			DisableKeepAlives: true,
+		}, nil
+	}
+}
+
+func probeTcp(ctx context.Context, hc *hcconf.TcpHealthCheck, tlsConf *tls.Config, addr string) error {
+	var conn net.Conn
+	var err error
+	if tlsConf != nil {
+		conn, err = (&tls.Dialer{Config: tlsConf}).DialContext(ctx, "tcp", addr)
+	} else {
+		conn, err = (&net.Dialer{}).DialContext(ctx, "tcp", addr)
+	}
+	if err != nil {
+		return err
+	}
+	defer conn.Close() // nolint:errcheck
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline) // nolint:errcheck
+	}
+
+	if hc.GetSend() != nil {
+		_, err = conn.Write(PayloadBytes(hc.GetSend()))
+		if err != nil {
+			return fmt.Errorf("failed to send payload: %s", err)
+		}
+	}
+	if len(hc.GetReceive()) == 0 {
+		return nil
+	}
+	received := make([]byte, 0)
+	buf := make([]byte, 4096)
+	for len(received) < maxReadSize {
+		n, err := conn.Read(buf)
+		received = append(received, buf[:n]...)
+		if MatchPayloads(received, hc.GetReceive()) {
+			return nil
+		}
+		if err != nil {
+			break
+		}
+	}
+	return fmt.Errorf("response does not contain %s", payloadsText(hc.GetReceive()))
+}
+
+func probeUdp(ctx context.Context, hc *hcconf.UdpHealthCheck, addr string) error {
+	conn, err := (&net.Dialer{}).DialContext(ctx, "udp", addr)
+	if err != nil {
+		return err
+	}
+	defer conn.Close() // nolint:errcheck
+
+	send := []byte(defaultUdpSend)
+	if hc.GetSend() != nil {
+		send = PayloadBytes(hc.GetSend())
+	}
+	_, err = conn.Write(send)
+	if err != nil {
+		return fmt.Errorf("failed to send payload: %s", err)
+	}
+
+	pingTimeout := hc.GetPingTimeout().AsDuration()
+	if pingTimeout <= 0 {
+		pingTimeout = 5 * time.Second
+	}
+	deadline := time.Now().Add(pingTimeout)
+	if ctxDeadline, ok := ctx.Deadline(); ok && ctxDeadline.Before(deadline) {
+		deadline = ctxDeadline
+	}
+	conn.SetReadDeadline(deadline) // nolint:errcheck
+
+	buf := make([]byte, 65535)
+	n, err := conn.Read(buf)
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		// no response is only an error when a payload is expected, a closed port would be refused
+		if len(hc.GetReceive()) == 0 {
+			return nil
+		}
+		return fmt.Errorf("no response received after %s", pingTimeout)
+	}
+	if err != nil {
+		return err
+	}
+	if !MatchPayloads(buf[:n], hc.GetReceive()) {
+		return fmt.Errorf("response does not contain %s", payloadsText(hc.GetReceive()))
+	}
+	return nil
+}
+
+func probeGrpc(ctx context.Context, hc *hcconf.GrpcHealthCheck, tlsConf *tls.Config, host, addr string) error {
+	creds := insecure.NewCredentials()
+	if tlsConf != nil {
+		creds = credentials.NewTLS(tlsConf)
+	}
+	authority := host
+	if hc.GetAuthority() != "" {
+		authority = hc.GetAuthority()
+	}
+	conn, err := grpc.DialContext(ctx, addr,
+		grpc.WithTransportCredentials(creds),
+		grpc.WithAuthority(authority),
+		grpc.WithBlock(),
+	)
+	if err != nil {
+		return err
+	}
+	defer conn.Close() // nolint:errcheck
+
+	resp, err := healthpb.NewHealthClient(conn).Check(ctx, &healthpb.HealthCheckRequest{
+		Service: hc.GetServiceName(),
+	})
+	if err != nil {
+		return err
+	}
+	if resp.GetStatus() != healthpb.HealthCheckResponse_SERVING {
+		return fmt.Errorf("service is %s", resp.GetStatus())
+	}
+	return nil
+}
+
+// probeIcmp send an echo request with an unprivileged icmp socket,
+// this must be allowed by system (e.g. sysctl net.ipv4.ping_group_range on linux).
+func probeIcmp(ctx context.Context, ip string) error {
+	dst := net.ParseIP(ip)
+	if dst == nil {
+		return fmt.Errorf("invalid ip %s", ip)
+	}
+	network, listenAddr, proto := "udp4", "0.0.0.0", 1
+	var echoType, replyType icmp.Type = ipv4.ICMPTypeEcho, ipv4.ICMPTypeEchoReply
+	if dst.To4() == nil {
+		network, listenAddr, proto = "udp6", "::", 58
+		echoType, replyType = ipv6.ICMPTypeEchoRequest, ipv6.ICMPTypeEchoReply
+	}
+	conn, err := icmp.ListenPacket(network, listenAddr)
+	if err != nil {
+		return fmt.Errorf("failed to open icmp socket, unprivileged ping may not be allowed on this host: %s", err)
+	}
+	defer conn.Close() // nolint:errcheck
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline) // nolint:errcheck
+	}
+
+	echo, err := (&icmp.Message{
+		Type: echoType,
+		Body: &icmp.Echo{ID: os.Getpid() & 0xffff, Seq: 1, Data: []byte(defaultUdpSend)},
+	}).Marshal(nil)
+	if err != nil {
+		return err
+	}
+	_, err = conn.WriteTo(echo, &net.UDPAddr{IP: dst})
+	if err != nil {
+		return fmt.Errorf("failed to send echo request: %s", err)
+	}
+	buf := make([]byte, 1500)
+	for {
+		n, peer, err := conn.ReadFrom(buf)
+		if err != nil {
+			return fmt.Errorf("no echo reply: %s", err)
+		}
+		if udpAddr, ok := peer.(*net.UDPAddr); !ok || !udpAddr.IP.Equal(dst) {
+			continue
+		}
+		reply, err := icmp.ParseMessage(proto, buf[:n])
+		if err != nil {
+			return err
+		}
+		if reply.Type == replyType {
+			return nil
+		}
+	}
+}
+
+// StatusExpected tells if code is in range, end is exclusive as in Int64Range
+// but a range with same start and end is a single status as made by --http-code.
+// Only 200 is expected when range is not set.
+func StatusExpected(rnge *gsloctype.Int64Range, code int) bool {
+	if rnge == nil || (rnge.GetStart() == 0 && rnge.GetEnd() == 0) {
+		return code == http.StatusOK
+	}
+	c := int64(code)
+	if rnge.GetStart() == rnge.GetEnd() {
+		return c == rnge.GetStart()
+	}
+	return c >= rnge.GetStart() && c < rnge.GetEnd()
+}
+
+// MatchPayloads does the "fuzzy" matching of gsloc, each payload must be found in order but not necessarily contiguous.
+func MatchPayloads(content []byte, payloads []*hcconf.HealthCheckPayload) bool {
+	for _, payload := range payloads {
+		b := PayloadBytes(payload)
+		i := bytes.Index(content, b)
+		if i < 0 {
+			return false
+		}
+		content = content[i+len(b):]
+	}
+	return true
+}
+
+// PayloadBytes gives raw content of a text or binary payload.
+func PayloadBytes(payload *hcconf.HealthCheckPayload) []byte {
+	if payload.GetText() != "" {
+		return []byte(payload.GetText())
+	}
+	return payload.GetBinary()
+}
+
+func payloadText(payload *hcconf.HealthCheckPayload) string {
+	if payload.GetText() != "" {
+		return strconv.Quote(payload.GetText())
+	}
+	return fmt.Sprintf("binary payload %x", payload.GetBinary())
+}
+
+func payloadsText(payloads []*hcconf.HealthCheckPayload) string {
+	texts := make([]string, len(payloads))
+	for i, payload := range payloads {
+		texts[i] = payloadText(payload)
+	}
+	return strings.Join(texts, " then ")
+}
+
+func rangeText(rnge *gsloctype.Int64Range) string {
+	if rnge == nil || (rnge.GetStart() == 0 && rnge.GetEnd() == 0) {
+		return "200"
+	}
+	if rnge.GetStart() == rnge.GetEnd() {
+		return strconv.FormatInt(rnge.GetStart(), 10)
+	}
+	return fmt.Sprintf("[%d, %d)", rnge.GetStart(), rnge.GetEnd())
+}
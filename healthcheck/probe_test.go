@@ -0,0 +1,190 @@
+package healthcheck
+
+import (
+	"context"
+	"encoding/pem"
+	"errors"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/orange-cloudfoundry/gsloc-go-sdk/gsloc/api/config/core/v1"
+	hcconf "github.com/orange-cloudfoundry/gsloc-go-sdk/gsloc/api/config/healthchecks/v1"
+	gsloctype "github.com/orange-cloudfoundry/gsloc-go-sdk/gsloc/type/v1"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+func serverPort(t *testing.T, addr net.Addr) uint32 {
+	_, port, err := net.SplitHostPort(addr.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, err := strconv.Atoi(port)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return uint32(p)
+}
+
+func TestProbeHttp(t *testing.T) {
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Host != "a.example.com" || r.Header.Get("X-Check") != "1" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.WriteHeader(http.StatusAccepted)
+		w.Write([]byte("status: ok")) // nolint:errcheck
+	}))
+	// handshake errors are expected when ca is missing
+	server.Config.ErrorLog = log.New(io.Discard, "", 0)
+	server.StartTLS()
+	defer server.Close()
+	ca := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+
+	makeHc := func(statuses *gsloctype.Int64Range, receive string) *hcconf.HealthCheck {
+		return &hcconf.HealthCheck{
+			Timeout: durationpb.New(2 * time.Second),
+			Port:    serverPort(t, server.Listener.Addr()),
+			TlsConfig: &hcconf.TlsConfig{
+				Enable:     true,
+				Ca:         string(ca),
+				ServerName: "example.com",
+			},
+			HealthChecker: &hcconf.HealthCheck_HttpHealthCheck{HttpHealthCheck: &hcconf.HttpHealthCheck{
+				Path:                "/health",
+				Receive:             textPayload(receive),
+				RequestHeadersToAdd: []*core.HeaderValueOption{{Header: &core.HeaderValue{Key: "X-Check", Value: "1"}}},
+				ExpectedStatuses:    statuses,
+			}},
+		}
+	}
+
+	err := Probe(context.Background(), makeHc(&gsloctype.Int64Range{Start: 200, End: 300}, "ok"), "a.example.com.", "127.0.0.1")
+	if err != nil {
+		t.Errorf("Expected http healthcheck to pass, got %s", err)
+	}
+	for name, hc := range map[string]*hcconf.HealthCheck{
+		"status":  makeHc(&gsloctype.Int64Range{Start: 200, End: 200}, "ok"),
+		"receive": makeHc(&gsloctype.Int64Range{Start: 200, End: 300}, "ko"),
+		"ca":      makeHc(&gsloctype.Int64Range{Start: 200, End: 300}, "ok"),
+	} {
+		if name == "ca" {
+			hc.TlsConfig.Ca = ""
+		}
+		if Probe(context.Background(), hc, "a.example.com.", "127.0.0.1") == nil {
+			t.Errorf("Expected http healthcheck to fail on %s", name)
+		}
+	}
+}
+
+func TestProbeTcpAndUdp(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close() // nolint:errcheck
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			buf := make([]byte, 4)
+			conn.Read(buf)                              // nolint:errcheck
+			conn.Write([]byte("pong, server is ready")) // nolint:errcheck
+			conn.Close()                                // nolint:errcheck
+		}
+	}()
+	udpConn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer udpConn.Close() // nolint:errcheck
+	go func() {
+		buf := make([]byte, 1024)
+		for {
+			n, addr, err := udpConn.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+			udpConn.WriteTo(buf[:n], addr) // nolint:errcheck
+		}
+	}()
+
+	cases := []struct {
+		name  string
+		hc    *hcconf.HealthCheck
+		valid bool
+	}{
+		{
+			name: "tcp receive in order",
+			hc: &hcconf.HealthCheck{Port: serverPort(t, listener.Addr()), HealthChecker: &hcconf.HealthCheck_TcpHealthCheck{TcpHealthCheck: &hcconf.TcpHealthCheck{
+				Send:    textPayload("ping"),
+				Receive: []*hcconf.HealthCheckPayload{textPayload("pong"), textPayload("ready")},
+			}}},
+			valid: true,
+		},
+		{
+			name: "tcp receive out of order",
+			hc: &hcconf.HealthCheck{Port: serverPort(t, listener.Addr()), HealthChecker: &hcconf.HealthCheck_TcpHealthCheck{TcpHealthCheck: &hcconf.TcpHealthCheck{
+				Send:    textPayload("ping"),
+				Receive: []*hcconf.HealthCheckPayload{textPayload("ready"), textPayload("pong")},
+			}}},
+		},
+		{
+			name: "udp echo",
+			hc: &hcconf.HealthCheck{Port: serverPort(t, udpConn.LocalAddr()), HealthChecker: &hcconf.HealthCheck_UdpHealthCheck{UdpHealthCheck: &hcconf.UdpHealthCheck{
+				Send:    textPayload("ping"),
+				Receive: []*hcconf.HealthCheckPayload{textPayload("ping")},
+			}}},
+			valid: true,
+		},
+		{
+			name: "udp unexpected",
+			hc: &hcconf.HealthCheck{Port: serverPort(t, udpConn.LocalAddr()), HealthChecker: &hcconf.HealthCheck_UdpHealthCheck{UdpHealthCheck: &hcconf.UdpHealthCheck{
+				Receive: []*hcconf.HealthCheckPayload{textPayload("pong")},
+			}}},
+		},
+	}
+	for _, tc := range cases {
+		tc.hc.Timeout = durationpb.New(time.Second)
+		err := Probe(context.Background(), tc.hc, "a.example.com.", "127.0.0.1")
+		if tc.valid && err != nil {
+			t.Errorf("%s: expected healthcheck to pass, got %s", tc.name, err)
+		}
+		if !tc.valid && err == nil {
+			t.Errorf("%s: expected healthcheck to fail", tc.name)
+		}
+	}
+}
+
+func TestProbeNotTestable(t *testing.T) {
+	hc := &hcconf.HealthCheck{HealthChecker: &hcconf.HealthCheck_PluginHealthCheck{PluginHealthCheck: &hcconf.PluginHealthCheck{Name: "my-plugin"}}}
+	if err := Probe(context.Background(), hc, "a.example.com.", "127.0.0.1"); !errors.Is(err, ErrNotTestable) {
+		t.Errorf("Expected plugin healthcheck to not be testable, got %v", err)
+	}
+}
+
+func TestStatusExpected(t *testing.T) {
+	cases := []struct {
+		rnge     *gsloctype.Int64Range
+		code     int
+		expected bool
+	}{
+		{nil, 200, true},
+		{nil, 204, false},
+		{&gsloctype.Int64Range{Start: 204, End: 204}, 204, true},
+		{&gsloctype.Int64Range{Start: 200, End: 300}, 299, true},
+		{&gsloctype.Int64Range{Start: 200, End: 300}, 300, false},
+	}
+	for _, tc := range cases {
+		if StatusExpected(tc.rnge, tc.code) != tc.expected {
+			t.Errorf("Expected %d in %v to be %t", tc.code, tc.rnge, tc.expected)
+		}
+	}
+}
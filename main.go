@@ -1,6 +1,7 @@
 package main
 
 import (
+	"errors"
 	"github.com/orange-cloudfoundry/gsloc-cli/cli"
 	"os"
 
@@ -16,6 +17,13 @@ var (
 func main() {
 	err := cli.Start(version, commit, date)
 	if err != nil {
+		var exitErr *cli.ExitError
+		if errors.As(err, &exitErr) {
+			if exitErr.Message != "" {
+				msg.Error(exitErr.Message)
+			}
+			os.Exit(exitErr.Code)
+		}
 		msg.Error(err.Error())
 		os.Exit(1)
 	}